@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+	"github.com/spf13/cobra"
+)
+
+var authHost string
+
+var authCmd = &cobra.Command{
+	Use:     "auth",
+	Short:   "Manage the GitHub tokens used to rotate secrets",
+	GroupID: "core-commands",
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a GitHub token in the local encrypted credential store",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthLogin(authHost)
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the GitHub token for a host is found",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, source, err := github.NewClient(authHost, github.Auth{Passphrase: passphrasePrompt})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using %s for %s\n", source, hostName(authHost))
+		return nil
+	},
+}
+
+func runAuthLogin(host string) error {
+	name, err := github.HostName(host)
+	if err != nil {
+		return err
+	}
+	store, err := github.DefaultCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	// A new store needs its passphrase confirmed, since it cannot be recovered.
+	prompt := passphrasePrompt
+	if !store.Exists() {
+		prompt = func() (string, error) {
+			return doubleEntryPrompt(secretPrompt)("Passphrase for the new credential store")
+		}
+	}
+	passphrase, err := prompt()
+	if err != nil {
+		return fmt.Errorf("failed to read passphrase: %v", err)
+	}
+	if passphrase == "" {
+		return fmt.Errorf("the passphrase must not be empty")
+	}
+	tokens, err := store.Load(passphrase)
+	if err != nil {
+		return err
+	}
+
+	token, err := secretPrompt(fmt.Sprintf("GitHub token for %s", name))
+	if err != nil {
+		return fmt.Errorf("failed to read token: %v", err)
+	}
+	if token == "" {
+		return fmt.Errorf("the token must not be empty")
+	}
+
+	tokens[name] = token
+	if err := store.Save(passphrase, tokens); err != nil {
+		return err
+	}
+	fmt.Printf("Stored the token for %s in %s\n", name, store.Path)
+	return nil
+}
+
+// authenticate creates the clients for every host used by the configuration and prints where their credentials were found.
+// The passphrase of the credential store is only prompted for when interactive is set.
+func authenticate(cfg config.KeyConfig, interactive bool) (*github.Clients, error) {
+	auth := github.Auth{App: cfg.GitHubApp, TokenHelper: cfg.TokenHelper}
+	if interactive {
+		auth.Passphrase = passphrasePrompt
+	}
+	clients, err := github.NewClients(cfg.Hosts(), auth)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range cfg.Hosts() {
+		fmt.Printf("Using %s for %s\n", clients.Source(host), hostName(host))
+	}
+	return clients, nil
+}
+
+// passphrasePrompt prompts for the passphrase of the credential store.
+func passphrasePrompt() (string, error) {
+	return secretPrompt("Credential store passphrase")
+}
+
+// hostName returns the name of a host setting for display.
+func hostName(host string) string {
+	if host == "" {
+		return github.DefaultHost
+	}
+	return host
+}
+
+func init() {
+	authCmd.PersistentFlags().StringVar(&authHost, "host", github.DefaultHost, "The GitHub host, e.g. github.example.com")
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authStatusCmd)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+	"github.com/spf13/cobra"
+)
+
+var checkParallel int
+
+var checkCmd = &cobra.Command{
+	Use:     "check <path to YAML config file>",
+	Short:   "Check that every destination exists and can be updated, without rotating anything",
+	Args:    cobra.ExactArgs(1),
+	GroupID: "core-commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if checkParallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1, got %d", checkParallel)
+		}
+		return runCheck(args[0], checkParallel)
+	},
+}
+
+func runCheck(yamlFile string, parallel int) error {
+	cfg, err := config.ParseFile(yamlFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %v", err)
+	}
+
+	clients, err := authenticate(cfg, true)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	printTokenScopes(ctx, clients, cfg.Hosts())
+
+	all := checkSecrets(ctx, clients, cfg.Secrets, parallel)
+	fmt.Println()
+	if err := printCheckResults(os.Stdout, all); err != nil {
+		return fmt.Errorf("failed to print results: %v", err)
+	}
+	return newCheckError(all)
+}
+
+// printTokenScopes prints the scopes of the token used for each host.
+// Scopes that cannot be read are reported as unknown, since the destinations are still checked.
+func printTokenScopes(ctx context.Context, clients *github.Clients, hosts []string) {
+	for _, host := range hosts {
+		scopes, ok, err := clients.TokenScopes(ctx, host)
+		switch {
+		case err != nil:
+			fmt.Printf("The scopes of the token for %s are unknown: %v\n", hostName(host), err)
+		case !ok:
+			fmt.Printf("The credentials for %s have fine-grained permissions, which are checked for each destination\n", hostName(host))
+		case len(scopes) == 0:
+			fmt.Printf("The token for %s has no scopes\n", hostName(host))
+		default:
+			fmt.Printf("The token for %s has the scopes: %s\n", hostName(host), strings.Join(scopes, ", "))
+		}
+	}
+}
+
+// checkSecrets checks that every destination of the secrets exists and can be updated,
+// running up to parallel checks at a time. Destinations that cannot be checked are reported as skipped.
+// The results are returned in the same order as the secrets and their destinations.
+func checkSecrets(ctx context.Context, clients *github.Clients, secrets []config.Secret, parallel int) []secretResults {
+	all := make([]secretResults, len(secrets))
+	semaphore := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+
+	for i, secret := range secrets {
+		all[i] = secretResults{secret: secret.Name, results: make([]destinationResult, len(secret.Destinations))}
+		for j, d := range secret.Destinations {
+			result := &all[i].results[j]
+			result.destination = d
+
+			checker, ok := d.Destination.(config.Checker)
+			if !ok {
+				result.skipped = true
+				continue
+			}
+
+			semaphore <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				client, err := destinationClient(ctx, clients, d)
+				if err == nil {
+					err = checker.Check(ctx, client)
+				}
+				result.err = err
+			}()
+		}
+	}
+	wg.Wait()
+	return all
+}
+
+// printCheckResults writes a table of the outcome of checking every destination.
+func printCheckResults(w io.Writer, all []secretResults) error {
+	return printTable(w, all, func(result destinationResult) string {
+		switch {
+		case result.skipped:
+			return "not checked"
+		case result.err != nil:
+			return "failed"
+		}
+		return "ok"
+	})
+}
+
+// checkError lists every destination that failed the check.
+type checkError struct {
+	Failures []destinationFailure
+}
+
+// newCheckError returns a checkError for the destinations that failed the check, or nil if none failed.
+func newCheckError(all []secretResults) error {
+	failures := destinationFailures(all)
+	if len(failures) == 0 {
+		return nil
+	}
+	return &checkError{Failures: failures}
+}
+
+func (e *checkError) Error() string {
+	return fmt.Sprintf("%d %s failed the check:%s", len(e.Failures), plural(len(e.Failures), "destination", "destinations"), listFailures(e.Failures))
+}
+
+func (e *checkError) Unwrap() []error {
+	return failureErrors(e.Failures)
+}
+
+func init() {
+	checkCmd.Flags().IntVar(&checkParallel, "parallel", 1, "Number of destinations to check concurrently")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+// checkedDestination is a fakeDestination that can be checked.
+type checkedDestination struct {
+	fakeDestination
+	checkErr error
+	checked  bool
+}
+
+func (d *checkedDestination) Check(_ context.Context, _ github.Client) error {
+	if d.tracker != nil {
+		d.tracker.mu.Lock()
+		d.tracker.current++
+		d.tracker.peak = max(d.tracker.peak, d.tracker.current)
+		d.tracker.mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		d.tracker.mu.Lock()
+		d.tracker.current--
+		d.tracker.mu.Unlock()
+	}
+	d.checked = true
+	return d.checkErr
+}
+
+func Test_checkSecrets(t *testing.T) {
+	errCheck := errors.New("repository not found")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	clients, err := github.NewClients([]string{""}, github.Auth{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ok := &checkedDestination{fakeDestination: fakeDestination{name: "repo-a"}}
+	failing := &checkedDestination{fakeDestination: fakeDestination{name: "repo-b"}, checkErr: errCheck}
+	unchecked := &fakeDestination{name: "other"}
+	secrets := []config.Secret{
+		{
+			Name: "api-key",
+			Destinations: []config.DestinationWrapper{
+				{Destination: ok},
+				{Destination: failing},
+			},
+		},
+		{
+			Name:         "token",
+			Destinations: []config.DestinationWrapper{{Destination: unchecked}},
+		},
+	}
+
+	all := checkSecrets(context.Background(), clients, secrets, 2)
+	if !ok.checked || !failing.checked {
+		t.Errorf("Expected both checkable destinations to be checked")
+	}
+	if ok.updated || failing.updated || unchecked.updated {
+		t.Errorf("Expected no destination to be updated")
+	}
+
+	var buf bytes.Buffer
+	if err := printCheckResults(&buf, all); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantTable := "SECRET   DESTINATION  RESULT\n" +
+		"api-key  repo-a       ok\n" +
+		"api-key  repo-b       failed\n" +
+		"token    other        not checked\n"
+	if buf.String() != wantTable {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", wantTable, buf.String())
+	}
+
+	err = newCheckError(all)
+	if !errors.Is(err, errCheck) {
+		t.Errorf("Expected error to wrap %v, got %v", errCheck, err)
+	}
+	wantMessage := "1 destination failed the check:\n- repo-b (api-key): repository not found"
+	if err.Error() != wantMessage {
+		t.Errorf("Expected error %q, got %q", wantMessage, err.Error())
+	}
+
+	// The destinations of a resumed secret that were already updated are checked too.
+	err = preflight(context.Background(), clients, secrets, [][]config.DestinationWrapper{{{Destination: ok}}, nil}, 1)
+	if !errors.Is(err, errCheck) {
+		t.Errorf("Expected every destination of the resumed secret to be checked, got %v", err)
+	}
+	err = preflight(context.Background(), clients, secrets, [][]config.DestinationWrapper{nil, {{Destination: unchecked}}}, 1)
+	if err != nil {
+		t.Errorf("Expected the pending secrets to pass the check, got %v", err)
+	}
+}
+
+func Test_checkSecrets_Parallel(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	clients, err := github.NewClients([]string{""}, github.Auth{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The limit applies across secrets, so that many small secrets are checked as fast as one large one.
+	tracker := &concurrencyTracker{}
+	var secrets []config.Secret
+	var fakes []*checkedDestination
+	for i := range 6 {
+		fake := &checkedDestination{fakeDestination: fakeDestination{name: string(rune('a' + i)), tracker: tracker}}
+		fakes = append(fakes, fake)
+		secrets = append(secrets, config.Secret{Name: fake.name, Destinations: []config.DestinationWrapper{{Destination: fake}}})
+	}
+
+	checkSecrets(context.Background(), clients, secrets, 3)
+	if tracker.peak != 3 {
+		t.Errorf("Expected up to 3 concurrent checks, got %d", tracker.peak)
+	}
+	for _, fake := range fakes {
+		if !fake.checked {
+			t.Errorf("Expected %s to be checked", fake.name)
+		}
+	}
+}
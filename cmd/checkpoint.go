@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lucasmelin/key-rotator/config"
+)
+
+// checkpointVersion is the version of the checkpoint file format.
+const checkpointVersion = 1
+
+// checkpoint records the progress of a rotation so that an interrupted run can be resumed.
+// Destinations are stored as hashes and values only as their fingerprint; secret values are never written.
+type checkpoint struct {
+	path string
+	mu   sync.Mutex
+	// skipped holds the secrets the user declined to rotate during this run.
+	skipped map[string]bool
+
+	Version int                          `json:"version"`
+	Secrets map[string]*secretCheckpoint `json:"secrets"`
+}
+
+// secretCheckpoint is the progress of a single secret.
+type secretCheckpoint struct {
+	// Fingerprint identifies the value written to the completed destinations.
+	Fingerprint string `json:"fingerprint"`
+	// Completed holds the keys of the destinations that were updated.
+	Completed []string `json:"completed"`
+}
+
+// checkpointPath returns the path of the checkpoint file for a configuration file.
+func checkpointPath(yamlFile string) string {
+	return filepath.Join(filepath.Dir(yamlFile), "."+filepath.Base(yamlFile)+".checkpoint.json")
+}
+
+// newCheckpoint returns an empty checkpoint stored at path.
+func newCheckpoint(path string) *checkpoint {
+	return &checkpoint{
+		path:    path,
+		skipped: map[string]bool{},
+		Version: checkpointVersion,
+		Secrets: map[string]*secretCheckpoint{},
+	}
+}
+
+// loadCheckpoint reads the checkpoint stored at path.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := newCheckpoint(path)
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %v", path, err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d in %s", cp.Version, path)
+	}
+	if cp.Secrets == nil {
+		cp.Secrets = map[string]*secretCheckpoint{}
+	}
+	return cp, nil
+}
+
+// openCheckpoint returns the checkpoint for the configuration file, loading it when resuming.
+// A fresh rotation refuses to start while the checkpoint of an interrupted one exists, so that its progress is not lost.
+func openCheckpoint(yamlFile string, resume bool, dryRun bool) (*checkpoint, error) {
+	path := checkpointPath(yamlFile)
+	if resume {
+		cp, err := loadCheckpoint(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no interrupted rotation to resume, %s does not exist", path)
+		}
+		return cp, err
+	}
+	if _, err := os.Stat(path); err == nil && !dryRun {
+		return nil, fmt.Errorf("%s exists from an interrupted rotation, rerun with --resume to continue it or delete it to start over", path)
+	}
+	return newCheckpoint(path), nil
+}
+
+// destinationKey identifies a destination by a hash of its host, type and settings.
+func destinationKey(d config.DestinationWrapper) string {
+	key := fmt.Sprintf("%T %+v", d.Destination, d.Destination)
+	if d.Host != "" {
+		key = d.Host + " " + key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// pending returns the destinations of the secret that have not been updated yet.
+func (c *checkpoint) pending(secret config.Secret) []config.DestinationWrapper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	completed := map[string]bool{}
+	if s, ok := c.Secrets[secret.Name]; ok {
+		for _, key := range s.Completed {
+			completed[key] = true
+		}
+	}
+
+	var pending []config.DestinationWrapper
+	for _, d := range secret.Destinations {
+		if !completed[destinationKey(d)] {
+			pending = append(pending, d)
+		}
+	}
+	return pending
+}
+
+// matches reports whether the value with the fingerprint is the one already written
+// to the completed destinations of the secret, if any.
+func (c *checkpoint) matches(secretName string, fingerprint string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.Secrets[secretName]
+	return !ok || len(s.Completed) == 0 || s.Fingerprint == fingerprint
+}
+
+// start records the fingerprint of the value about to be written for the secret.
+// If it differs from the value written by the interrupted run, the progress of the
+// secret is discarded, since its completed destinations hold a different value.
+func (c *checkpoint) start(secretName string, fingerprint string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.Secrets[secretName]
+	if !ok || (len(s.Completed) > 0 && s.Fingerprint != fingerprint) {
+		s = &secretCheckpoint{}
+		c.Secrets[secretName] = s
+	}
+	s.Fingerprint = fingerprint
+	return c.save()
+}
+
+// complete records that the destination of the secret was updated.
+func (c *checkpoint) complete(secretName string, d config.DestinationWrapper) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.Secrets[secretName]
+	if !ok {
+		s = &secretCheckpoint{}
+		c.Secrets[secretName] = s
+	}
+	s.Completed = append(s.Completed, destinationKey(d))
+	return c.save()
+}
+
+// skip records that the user declined to rotate the secret. A deliberate skip is not an interruption,
+// so the progress of the secret is dropped and its destinations do not keep the checkpoint from being removed.
+func (c *checkpoint) skip(secretName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.skipped[secretName] = true
+	if _, ok := c.Secrets[secretName]; !ok {
+		return nil
+	}
+	delete(c.Secrets, secretName)
+	return c.save()
+}
+
+// save writes the checkpoint to disk, replacing the previous file atomically.
+func (c *checkpoint) save() error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %v", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+// finish removes the checkpoint once every destination of every secret that was not skipped has been updated.
+// Otherwise, it explains how to resume the rotation.
+func (c *checkpoint) finish(cfg config.KeyConfig) error {
+	for _, secret := range cfg.Secrets {
+		if c.skipped[secret.Name] {
+			continue
+		}
+		if len(c.pending(secret)) > 0 {
+			if _, err := os.Stat(c.path); err == nil {
+				fmt.Printf("Progress was saved to %s, rerun with --resume to update the remaining destinations.\n", c.path)
+			}
+			return nil
+		}
+	}
+	return c.remove()
+}
+
+// remove deletes the checkpoint file once the rotation has completed.
+func (c *checkpoint) remove() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+func Test_checkpointPath(t *testing.T) {
+	got := checkpointPath(filepath.Join("configs", "keys.yaml"))
+	want := filepath.Join("configs", ".keys.yaml.checkpoint.json")
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func Test_checkpoint(t *testing.T) {
+	yamlFile := filepath.Join(t.TempDir(), "keys.yaml")
+	repoA := config.DestinationWrapper{Destination: github.RepositorySecret{Repo: "octo/a", Name: "API_KEY"}}
+	repoB := config.DestinationWrapper{Destination: github.RepositorySecret{Repo: "octo/a", Name: "API_KEY"}, Host: "github.example.com"}
+	secret := config.Secret{
+		Name:         "api-key",
+		Destinations: []config.DestinationWrapper{repoA, repoB},
+	}
+	cfg := config.KeyConfig{Secrets: []config.Secret{secret}}
+
+	if _, err := openCheckpoint(yamlFile, true, false); err == nil {
+		t.Fatalf("Expected an error resuming without a checkpoint")
+	}
+
+	cp, err := openCheckpoint(yamlFile, false, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.start("api-key", fingerprint("first")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.complete("api-key", repoA); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.finish(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The value itself is never written to the checkpoint.
+	b, err := os.ReadFile(checkpointPath(yamlFile))
+	if err != nil {
+		t.Fatalf("Expected the checkpoint to be kept, got %v", err)
+	}
+	if strings.Contains(string(b), "first") {
+		t.Errorf("Expected the checkpoint not to contain the value, got %s", b)
+	}
+
+	if _, err := openCheckpoint(yamlFile, false, false); err == nil {
+		t.Errorf("Expected an error starting over while a checkpoint exists")
+	}
+	if _, err := openCheckpoint(yamlFile, false, true); err != nil {
+		t.Errorf("Expected a dry run to ignore the checkpoint, got %v", err)
+	}
+
+	resumed, err := openCheckpoint(yamlFile, true, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pending := resumed.pending(secret)
+	if len(pending) != 1 || pending[0] != repoB {
+		t.Fatalf("Expected only %s to be pending, got %v", repoB.GetDescription(), pending)
+	}
+	if !resumed.matches("api-key", fingerprint("first")) {
+		t.Errorf("Expected the same value to match")
+	}
+	if resumed.matches("api-key", fingerprint("second")) {
+		t.Errorf("Expected a different value not to match")
+	}
+
+	// A different value discards the progress of the secret.
+	if err := resumed.start("api-key", fingerprint("second")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := len(resumed.pending(secret)); got != 2 {
+		t.Errorf("Expected 2 pending destinations, got %d", got)
+	}
+
+	for _, d := range secret.Destinations {
+		if err := resumed.complete("api-key", d); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := resumed.finish(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(checkpointPath(yamlFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed, got %v", err)
+	}
+}
+
+func Test_checkpoint_Skip(t *testing.T) {
+	yamlFile := filepath.Join(t.TempDir(), "keys.yaml")
+	repoA := config.DestinationWrapper{Destination: github.RepositorySecret{Repo: "octo/a", Name: "API_KEY"}}
+	repoB := config.DestinationWrapper{Destination: github.RepositorySecret{Repo: "octo/b", Name: "TOKEN"}}
+	cfg := config.KeyConfig{Secrets: []config.Secret{
+		{Name: "api-key", Destinations: []config.DestinationWrapper{repoA}},
+		{Name: "token", Destinations: []config.DestinationWrapper{repoB}},
+	}}
+
+	cp, err := openCheckpoint(yamlFile, false, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.start("api-key", fingerprint("value")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.complete("api-key", repoA); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Declining the second secret is not an interruption.
+	if err := cp.skip("token"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := cp.finish(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(checkpointPath(yamlFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed, got %v", err)
+	}
+	if _, err := openCheckpoint(yamlFile, false, false); err != nil {
+		t.Errorf("Expected the next rotation to start, got %v", err)
+	}
+}
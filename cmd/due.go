@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/spf13/cobra"
+)
+
+// States of a secret in the due report.
+const (
+	dueOverdue = "overdue"
+	dueSoon    = "due soon"
+	dueOK      = "ok"
+	dueUnknown = "unknown"
+)
+
+// Exit codes of the due command, so that alerting can tell a secret coming due from an overdue one,
+// and a secret whose last rotation is unknown from a failed check. Any other error exits with 1.
+const (
+	exitDueSoon = 2
+	exitOverdue = 3
+	exitUnknown = 4
+)
+
+var dueWithin = config.Duration(14 * 24 * time.Hour)
+
+var dueCmd = &cobra.Command{
+	Use:     "due <path to YAML config file>",
+	Short:   "List the secrets that are overdue or coming due for rotation according to their max_age",
+	Args:    cobra.ExactArgs(1),
+	GroupID: "core-commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDue(args[0], time.Duration(dueWithin), time.Now())
+	},
+}
+
+// secretAge is when a secret was last rotated, compared to its maximum age.
+type secretAge struct {
+	secret string
+	maxAge time.Duration
+	// rotated is the oldest update of any of its destinations, since the secret is only as fresh as its stalest copy.
+	rotated time.Time
+	// missing counts the destinations the secret was never written to.
+	missing int
+	state   string
+}
+
+func runDue(yamlFile string, within time.Duration, now time.Time) error {
+	cfg, err := config.ParseFile(yamlFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %v", err)
+	}
+
+	var secrets []config.Secret
+	for _, secret := range cfg.Secrets {
+		if cfg.SecretMaxAge(secret) > 0 {
+			secrets = append(secrets, secret)
+		}
+	}
+	if skipped := len(cfg.Secrets) - len(secrets); skipped > 0 {
+		fmt.Printf("Skipping %d %s without a max_age\n", skipped, plural(skipped, "secret", "secrets"))
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	clients, err := authenticate(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	statuses := fetchStatuses(context.Background(), clients, secrets)
+	ages := make([]secretAge, len(statuses))
+	for i, s := range statuses {
+		ages[i] = dueState(s, cfg.SecretMaxAge(secrets[i]), within, now)
+	}
+
+	fmt.Println()
+	if err := printDue(os.Stdout, ages); err != nil {
+		return fmt.Errorf("failed to print due secrets: %v", err)
+	}
+	for _, s := range statuses {
+		for _, d := range s.destinations {
+			if d.err != nil {
+				fmt.Printf("Failed to get the status of %s: %v\n", d.destination.GetDescription(), d.err)
+			}
+		}
+	}
+	return dueError(ages)
+}
+
+// dueState compares when the secret was last rotated in every destination with its maximum age.
+// A secret missing from a destination is overdue, since it was never rotated there.
+// Destinations whose status cannot be read are left out, and a secret without any known update is unknown.
+func dueState(s secretStatuses, maxAge time.Duration, within time.Duration, now time.Time) secretAge {
+	age := secretAge{secret: s.secret, maxAge: maxAge}
+	for _, d := range s.destinations {
+		switch {
+		case d.err != nil || d.unsupported:
+		case !d.status.Exists:
+			age.missing++
+		case d.status.UpdatedAt.IsZero():
+		case age.rotated.IsZero() || d.status.UpdatedAt.Before(age.rotated):
+			age.rotated = d.status.UpdatedAt
+		}
+	}
+
+	due := age.rotated.Add(maxAge)
+	switch {
+	case age.missing > 0:
+		age.state = dueOverdue
+	case age.rotated.IsZero():
+		age.state = dueUnknown
+	case now.After(due):
+		age.state = dueOverdue
+	case due.Sub(now) <= within:
+		age.state = dueSoon
+	default:
+		age.state = dueOK
+	}
+	return age
+}
+
+// printDue writes a table of when each secret was last rotated and when it is due.
+func printDue(w io.Writer, ages []secretAge) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECRET\tMAX AGE\tLAST ROTATED\tDUE\tSTATE")
+	for _, age := range ages {
+		rotated, due := "-", "-"
+		if !age.rotated.IsZero() {
+			rotated = age.rotated.UTC().Format(time.RFC3339)
+			due = age.rotated.Add(age.maxAge).UTC().Format(time.RFC3339)
+		}
+		state := age.state
+		if age.missing > 0 {
+			state = fmt.Sprintf("%s, missing from %d %s", state, age.missing, plural(age.missing, "destination", "destinations"))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", age.secret, config.Duration(age.maxAge), rotated, due, state)
+	}
+	return tw.Flush()
+}
+
+// dueError returns an error with the exit code of the most urgent secret, or nil if none are due.
+func dueError(ages []secretAge) error {
+	var overdue, soon, unknown int
+	for _, age := range ages {
+		switch age.state {
+		case dueOverdue:
+			overdue++
+		case dueSoon:
+			soon++
+		case dueUnknown:
+			unknown++
+		}
+	}
+
+	switch {
+	case overdue > 0:
+		return &exitError{Code: exitOverdue, Err: fmt.Errorf("%d %s overdue for rotation", overdue, plural(overdue, "secret is", "secrets are"))}
+	case soon > 0:
+		return &exitError{Code: exitDueSoon, Err: fmt.Errorf("%d %s coming due for rotation", soon, plural(soon, "secret is", "secrets are"))}
+	case unknown > 0:
+		return &exitError{Code: exitUnknown, Err: fmt.Errorf("the last rotation of %d %s unknown", unknown, plural(unknown, "secret is", "secrets are"))}
+	}
+	return nil
+}
+
+func init() {
+	dueCmd.Flags().Var(&dueWithin, "within", "Report secrets that come due within this time, e.g. 14d")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+func Test_dueState(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	maxAge := 90 * 24 * time.Hour
+	within := 14 * 24 * time.Hour
+	updated := func(daysAgo int) destinationStatus {
+		return destinationStatus{status: github.SecretStatus{Exists: true, UpdatedAt: now.AddDate(0, 0, -daysAgo)}}
+	}
+
+	tests := []struct {
+		name         string
+		destinations []destinationStatus
+		wantState    string
+		wantRotated  time.Time
+		wantMissing  int
+	}{
+		{
+			name:         "recently rotated",
+			destinations: []destinationStatus{updated(10), updated(11)},
+			wantState:    dueOK,
+			wantRotated:  now.AddDate(0, 0, -11),
+		},
+		{
+			name:         "oldest destination coming due",
+			destinations: []destinationStatus{updated(10), updated(80)},
+			wantState:    dueSoon,
+			wantRotated:  now.AddDate(0, 0, -80),
+		},
+		{
+			name:         "overdue",
+			destinations: []destinationStatus{updated(91)},
+			wantState:    dueOverdue,
+			wantRotated:  now.AddDate(0, 0, -91),
+		},
+		{
+			name:         "missing from a destination",
+			destinations: []destinationStatus{updated(10), {}},
+			wantState:    dueOverdue,
+			wantRotated:  now.AddDate(0, 0, -10),
+			wantMissing:  1,
+		},
+		{
+			name:         "unreadable destinations are left out",
+			destinations: []destinationStatus{updated(10), {err: errors.New("permission denied")}, {unsupported: true}},
+			wantState:    dueOK,
+			wantRotated:  now.AddDate(0, 0, -10),
+		},
+		{
+			name:         "no known update",
+			destinations: []destinationStatus{{err: errors.New("permission denied")}},
+			wantState:    dueUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dueState(secretStatuses{secret: "api-key", destinations: tt.destinations}, maxAge, within, now)
+			if got.state != tt.wantState {
+				t.Errorf("Expected state %q, got %q", tt.wantState, got.state)
+			}
+			if !got.rotated.Equal(tt.wantRotated) {
+				t.Errorf("Expected last rotation %s, got %s", tt.wantRotated, got.rotated)
+			}
+			if got.missing != tt.wantMissing {
+				t.Errorf("Expected %d missing, got %d", tt.wantMissing, got.missing)
+			}
+		})
+	}
+}
+
+func Test_printDue(t *testing.T) {
+	rotated := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	ages := []secretAge{
+		{secret: "api-key", maxAge: 90 * 24 * time.Hour, rotated: rotated, state: dueOK},
+		{secret: "token", maxAge: 36 * time.Hour, rotated: rotated, missing: 2, state: dueOverdue},
+		{secret: "other", maxAge: 30 * 24 * time.Hour, state: dueUnknown},
+	}
+
+	var buf bytes.Buffer
+	if err := printDue(&buf, ages); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "SECRET   MAX AGE  LAST ROTATED          DUE                   STATE\n" +
+		"api-key  90d      2025-03-01T12:00:00Z  2025-05-30T12:00:00Z  ok\n" +
+		"token    36h0m0s  2025-03-01T12:00:00Z  2025-03-03T00:00:00Z  overdue, missing from 2 destinations\n" +
+		"other    30d      -                     -                     unknown\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func Test_dueError(t *testing.T) {
+	tests := []struct {
+		name     string
+		states   []string
+		wantCode int
+		wantNil  bool
+	}{
+		{name: "nothing due", states: []string{dueOK, dueOK}, wantNil: true},
+		{name: "coming due", states: []string{dueOK, dueSoon}, wantCode: exitDueSoon},
+		{name: "overdue takes precedence", states: []string{dueSoon, dueOverdue}, wantCode: exitOverdue},
+		{name: "unknown", states: []string{dueOK, dueUnknown}, wantCode: exitUnknown},
+		{name: "coming due takes precedence over unknown", states: []string{dueUnknown, dueSoon}, wantCode: exitDueSoon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ages []secretAge
+			for _, state := range tt.states {
+				ages = append(ages, secretAge{state: state})
+			}
+			err := dueError(ages)
+			if tt.wantNil {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			// The code must survive wrapping, since Execute only sees the final error.
+			if got := exitCode(fmt.Errorf("wrapped: %w", err)); got != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+// exitError is an error that exits with a specific code, so that scripts and alerting can tell outcomes apart.
+type exitError struct {
+	Code int
+	Err  error
+}
+
+func (e *exitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode returns the code to exit with after the error.
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
+// errorHint suggests how to fix a known error, or returns an empty string.
+func errorHint(err error) string {
+	var missingCredentials *github.MissingCredentialsError
+	var invalidRepo *github.InvalidRepoFormatError
+	var repoNotFound *github.RepoNotFoundError
+	var permissionDenied *github.PermissionDeniedError
+	var keyFetch *github.KeyFetchError
+
+	switch {
+	case errors.As(err, &missingCredentials):
+		return "Log in with `gh auth login --hostname " + missingCredentials.Host + "`, store a token with `key-rotator auth login --host " + missingCredentials.Host + "`, or export one, e.g. `export " + missingCredentials.Variables[0] + "=your_github_token`."
+	case errors.As(err, &invalidRepo):
+		return "Repositories must be written as owner/repo, e.g. octo-org/octo-repo."
+	case errors.As(err, &repoNotFound):
+		return "Check the spelling of " + repoNotFound.Repo + ". GitHub also reports private repositories the token cannot access as not found."
+	case errors.As(err, &permissionDenied):
+		return "Check that the token is valid and has admin access to " + permissionDenied.Resource + ", with the repo, admin:org or codespace scopes the destination requires."
+	case errors.As(err, &keyFetch):
+		return "The public key used to encrypt the secret could not be fetched, check that the token can manage secrets for " + keyFetch.Scope + "."
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+func Test_errorHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "missing credentials",
+			err:  &github.MissingCredentialsError{Host: "github.com", Variables: []string{"GITHUB_TOKEN"}},
+			want: "export GITHUB_TOKEN=",
+		},
+		{
+			name: "wrapped repository not found",
+			err:  fmt.Errorf("failed to update secret: %w", &github.RepoNotFoundError{Repo: "o/r", Err: errors.New("404")}),
+			want: "Check the spelling of o/r",
+		},
+		{
+			name: "permission denied inside a key fetch",
+			err: &github.KeyFetchError{
+				Scope: "actions/orgs/o",
+				Err:   &github.PermissionDeniedError{Resource: "o", Err: errors.New("403")},
+			},
+			want: "admin access to o",
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("boom"),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorHint(tt.err)
+			if tt.want == "" && got != "" {
+				t.Errorf("Expected no hint, got %q", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Expected hint containing %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
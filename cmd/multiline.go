@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var errPromptCancelled = errors.New("cancelled by the user")
+
+// multilineModel is a masked, multi-line input for values such as PEM keys or JSON credentials.
+// The value is never displayed, only its size.
+type multilineModel struct {
+	title     string
+	value     []rune
+	done      bool
+	cancelled bool
+}
+
+func (m multilineModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m multilineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlD:
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyEnter, tea.KeyCtrlJ:
+		// Enter is received as a carriage return and ctrl+j as a line feed, both typed as a line break.
+		m.value = append(m.value, '\n')
+	case tea.KeyTab:
+		m.value = append(m.value, '\t')
+	case tea.KeySpace:
+		m.value = append(m.value, ' ')
+	case tea.KeyBackspace:
+		if len(m.value) > 0 {
+			m.value = m.value[:len(m.value)-1]
+		}
+	case tea.KeyRunes:
+		// Pasted values are kept byte for byte, including CRLF line endings.
+		m.value = append(m.value, keyMsg.Runes...)
+	}
+	return m, nil
+}
+
+func (m multilineModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+	return fmt.Sprintf("%s\nPaste or type the value, then press ctrl+d to finish (esc to cancel).\n%s\n", m.title, describeValue(string(m.value)))
+}
+
+// multilineSecretPrompt prompts for a multi-line value without echoing it.
+func multilineSecretPrompt(title string) (string, error) {
+	result, err := tea.NewProgram(multilineModel{title: title}).Run()
+	if err != nil {
+		return "", err
+	}
+	m := result.(multilineModel)
+	if m.cancelled {
+		return "", errPromptCancelled
+	}
+	return string(m.value), nil
+}
+
+// describeValue summarizes a value by its exact length and line count, without revealing it.
+func describeValue(value string) string {
+	lines := strings.Count(value, "\n")
+	if value != "" && !strings.HasSuffix(value, "\n") {
+		lines++
+	}
+
+	description := fmt.Sprintf("%d bytes, %d %s", len(value), lines, plural(lines, "line", "lines"))
+	if strings.HasSuffix(value, "\n") {
+		description += ", ends with a newline"
+	}
+	return description
+}
+
+func plural(n int, singular string, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
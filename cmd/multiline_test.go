@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func Test_multilineModel_Update(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want string
+	}{
+		{
+			name: "typed lines",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("a")},
+				{Type: tea.KeyEnter},
+				{Type: tea.KeyRunes, Runes: []rune("b")},
+				{Type: tea.KeyCtrlJ},
+				{Type: tea.KeySpace},
+				{Type: tea.KeyTab},
+			},
+			want: "a\nb\n \t",
+		},
+		{
+			name: "pasted PEM key",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("-----BEGIN KEY-----\nabc\n-----END KEY-----\n"), Paste: true},
+			},
+			want: "-----BEGIN KEY-----\nabc\n-----END KEY-----\n",
+		},
+		{
+			name: "pasted CRLF line endings are kept",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("a\r\nb"), Paste: true},
+			},
+			want: "a\r\nb",
+		},
+		{
+			name: "backspace",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune("ab")},
+				{Type: tea.KeyBackspace},
+				{Type: tea.KeyBackspace},
+				{Type: tea.KeyBackspace},
+				{Type: tea.KeyRunes, Runes: []rune("c")},
+			},
+			want: "c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m tea.Model = multilineModel{}
+			for _, key := range tt.keys {
+				m, _ = m.Update(key)
+			}
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+
+			got := m.(multilineModel)
+			if !got.done {
+				t.Error("Expected ctrl+d to finish the input")
+			}
+			if string(got.value) != tt.want {
+				t.Errorf("value = %q, want %q", string(got.value), tt.want)
+			}
+		})
+	}
+}
+
+func Test_describeValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  "0 bytes, 0 lines",
+		},
+		{
+			name:  "single line",
+			value: "secret",
+			want:  "6 bytes, 1 line",
+		},
+		{
+			name:  "trailing newline",
+			value: "secret\n",
+			want:  "7 bytes, 1 line, ends with a newline",
+		},
+		{
+			name:  "multiple lines",
+			value: "line one\nline two",
+			want:  "17 bytes, 2 lines",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeValue(tt.value)
+			if got != tt.want {
+				t.Errorf("describeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
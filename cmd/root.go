@@ -1,20 +1,26 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:          "key-rotator",
-	Short:        "Rotate your secrets from the command line.",
-	SilenceUsage: true,
+	Use:           "key-rotator",
+	Short:         "Rotate your secrets from the command line.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -29,5 +35,9 @@ func init() {
 	rootCmd.SetHelpCommandGroupID("additional-commands")
 	rootCmd.SetCompletionCommandGroupID("additional-commands")
 	rootCmd.AddCommand(rotateCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(dueCmd)
+	rootCmd.AddCommand(authCmd)
 	rootCmd.AddCommand(versionCmd)
 }
@@ -2,7 +2,13 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/lucasmelin/key-rotator/config"
@@ -10,26 +16,75 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var dryRun bool
+var (
+	dryRun         string
+	nonInteractive bool
+	multiline      bool
+	confirmInput   bool
+	parallel       int
+	keepGoing      bool
+	resume         bool
+	skipCheck      bool
+	runCommands    bool
+)
+
+// dryRunDeep is the --dry-run mode that verifies every update up to the request
+// that would write the value, which is not sent.
+const dryRunDeep = "deep"
 
 type rotateOptions struct {
-	dryRun   bool
-	yamlFile string
+	dryRun         bool
+	deepDryRun     bool
+	nonInteractive bool
+	multiline      bool
+	confirmInput   bool
+	parallel       int
+	keepGoing      bool
+	resume         bool
+	skipCheck      bool
+	runCommands    bool
+	yamlFile       string
+	stdin          io.Reader
 }
 
 var rotateCmd = &cobra.Command{
 	Use:     "rotate <path to YAML config file>",
 	Short:   "Rotate secrets based on the provided configuration file",
-	Args:    cobra.ExactArgs(1),
+	Args:    cobra.RangeArgs(1, 2),
 	GroupID: "core-commands",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if dryRun {
+		// A second argument may be the value of --dry-run.
+		value, args := splitDryRunArg(cmd.Flags().Changed("dry-run"), dryRun, args)
+		if len(args) != 1 {
+			return fmt.Errorf("accepts 1 arg(s), received %d", len(args))
+		}
+		dryRun, deepDryRun, err := parseDryRun(value)
+		if err != nil {
+			return err
+		}
+		switch {
+		case deepDryRun:
+			fmt.Println("Running in deep dry-run mode, every update is verified but no changes will be made")
+		case dryRun:
 			fmt.Println("Running in dry-run mode, no changes will be made")
 		}
+		if parallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1, got %d", parallel)
+		}
 
 		opts := &rotateOptions{
-			dryRun:   dryRun,
-			yamlFile: args[0],
+			dryRun:         dryRun,
+			deepDryRun:     deepDryRun,
+			nonInteractive: nonInteractive,
+			multiline:      multiline,
+			confirmInput:   confirmInput,
+			parallel:       parallel,
+			keepGoing:      keepGoing,
+			resume:         resume,
+			skipCheck:      skipCheck,
+			runCommands:    runCommands,
+			yamlFile:       args[0],
+			stdin:          os.Stdin,
 		}
 
 		return runRotate(opts)
@@ -43,51 +98,340 @@ func runRotate(opts *rotateOptions) error {
 		return fmt.Errorf("failed to parse file: %v", err)
 	}
 
-	// Create a new GitHub client.
-	client := github.NewClient()
+	// Reading a value from stdin leaves nothing to answer the confirmation prompt with.
+	if cfg.UsesStdin() && !opts.nonInteractive {
+		return fmt.Errorf("reading a secret value from stdin requires --non-interactive")
+	}
+
+	// A command may create a real credential upstream, e.g. a new cloud key,
+	// so a deep dry run only runs it when explicitly asked to.
+	if opts.deepDryRun && !opts.runCommands {
+		for _, secret := range cfg.Secrets {
+			if command := sourceCommand(secret); command != nil {
+				return fmt.Errorf("the value of %s is read by running %q, which may create a real credential; pass --run-commands to run it during a deep dry run", secret.Name, strings.Join(command, " "))
+			}
+		}
+	}
+
+	// Track progress so that an interrupted rotation can be resumed.
+	cp, err := openCheckpoint(opts.yamlFile, opts.resume, opts.dryRun)
+	if err != nil {
+		return err
+	}
+	if !opts.dryRun {
+		defer func() {
+			if err := cp.finish(cfg); err != nil {
+				fmt.Println("Warning:", err)
+			}
+		}()
+	}
+
+	// Skip the secrets whose destinations were all updated by the interrupted rotation.
+	pending := make([][]config.DestinationWrapper, len(cfg.Secrets))
+	for i, secret := range cfg.Secrets {
+		pending[i] = cp.pending(secret)
+		if len(pending[i]) == 0 {
+			fmt.Printf("Skipping %s, all of its destinations were already updated.\n", secret.Name)
+		}
+	}
+
+	// Create a GitHub client for each host used by the destinations.
+	clients, err := authenticate(cfg, !opts.nonInteractive)
+	if err != nil {
+		return err
+	}
 	ctx := context.Background()
 
+	// Check every destination before any value is requested, so that a typo in the
+	// configuration is found before the upstream credential is rotated.
+	if !opts.skipCheck {
+		if err := preflight(ctx, clients, cfg.Secrets, pending, opts.parallel); err != nil {
+			return err
+		}
+	}
+
+	// In non-interactive mode, resolve every value up front so that a missing
+	// value fails the run before any destination is updated.
+	values := make([]string, len(cfg.Secrets))
+	if opts.nonInteractive {
+		for i, secret := range cfg.Secrets {
+			if len(pending[i]) == 0 {
+				continue
+			}
+			if values[i], err = resolveSecretValue(secret, opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Results of every secret that was updated, reported at the end with --keep-going.
+	var all []secretResults
+
 	// Iterate over each secret in the configuration.
-	for _, secret := range cfg.Secrets {
-		// Prompt the user to enter the value for the secret.
-		secretValue, err := secretPrompt(fmt.Sprintf("%s: %s", secret.Name, secret.Description))
-		if err != nil {
-			return fmt.Errorf("failed to read input: %v", err)
+	for i, secret := range cfg.Secrets {
+		destinations := pending[i]
+		if len(destinations) == 0 {
+			continue
+		}
+
+		secretValue := values[i]
+		if !opts.nonInteractive {
+			if secretValue, err = resolveSecretValue(secret, opts); err != nil {
+				return err
+			}
+		}
+
+		// A shallow dry run does not run commands, so there is no value to describe.
+		if !skipsCommand(secret, opts) {
+			// Display the size of the value so it can be checked before confirming.
+			fmt.Printf("Value for %s: %s (SHA-256 %s)\n", secret.Name, describeValue(secretValue), fingerprint(secretValue))
+
+			// Destinations already updated by the interrupted rotation hold a different value.
+			if !cp.matches(secret.Name, fingerprint(secretValue)) {
+				fmt.Printf("The value differs from the one used by the interrupted rotation, so all destinations of %s will be updated again.\n", secret.Name)
+				destinations = secret.Destinations
+			}
 		}
 
 		// Display the destinations that will be updated.
 		fmt.Println("The following destinations will be updated:")
-		for _, d := range secret.Destinations {
-			fmt.Println("-", d.Destination.GetDescription())
+		for _, d := range destinations {
+			fmt.Println("-", d.GetDescription())
+		}
+		if skipped := len(secret.Destinations) - len(destinations); skipped > 0 {
+			fmt.Printf("(%d %s already updated by the interrupted rotation)\n", skipped, plural(skipped, "destination was", "destinations were"))
 		}
 
 		// Prompt the user to accept before updating.
-		confirm, err := confirmPrompt("Do you want to proceed with updating these destinations? (y/N)")
-		if err != nil {
-			return fmt.Errorf("failed to read input: %v", err)
+		if !opts.nonInteractive {
+			confirm, err := confirmPrompt("Do you want to proceed with updating these destinations? (y/N)")
+			if err != nil {
+				return fmt.Errorf("failed to read input: %v", err)
+			}
+			if !confirm {
+				fmt.Printf("Skipped %s, none of its destinations were updated.\n", secret.Name)
+				if !opts.dryRun {
+					if err := cp.skip(secret.Name); err != nil {
+						return err
+					}
+				}
+				continue
+			}
 		}
-		if !confirm {
-			fmt.Println("Operation cancelled by the user.")
+
+		if opts.dryRun && !opts.deepDryRun {
+			for _, d := range destinations {
+				fmt.Printf("[Dry Run] Would update %s with provided secret value for %s\n", d.GetDescription(), secret.Name)
+			}
 			continue
 		}
 
-		// Iterate over each destination for the secret.
-		for _, d := range secret.Destinations {
-			if opts.dryRun {
-				fmt.Printf("[Dry Run] Would update %s with provided secret value for %s\n", d.Destination.GetDescription(), secret.Name)
-			} else {
-				if err = d.Destination.UpdateSecret(ctx, client, secretValue); err != nil {
-					return fmt.Errorf("failed to update secret: %v", err)
+		// A deep dry run updates nothing, so there is no progress to record.
+		onUpdated := func(config.DestinationWrapper) {}
+		if !opts.dryRun {
+			if err := cp.start(secret.Name, fingerprint(secretValue)); err != nil {
+				return err
+			}
+			onUpdated = func(d config.DestinationWrapper) {
+				if err := cp.complete(secret.Name, d); err != nil {
+					fmt.Println("Warning:", err)
+				}
+			}
+		}
+
+		// Update the destinations for the secret, several at a time if requested,
+		// recording each one as soon as it is updated.
+		results := updateDestinations(ctx, clients, destinations, secretValue, opts, onUpdated)
+		if !opts.keepGoing {
+			if err := firstError(results); err != nil {
+				if opts.deepDryRun {
+					return fmt.Errorf("dry run of the update failed: %w", err)
 				}
-				fmt.Println("Updated", d.Destination.GetDescription())
+				return fmt.Errorf("failed to update secret: %w", err)
 			}
 		}
+		all = append(all, secretResults{secret: secret.Name, results: results})
+	}
+
+	if opts.keepGoing && len(all) > 0 {
+		fmt.Println()
+		printTable := printResults
+		if opts.deepDryRun {
+			printTable = printDryRunResults
+		}
+		if err := printTable(os.Stdout, all); err != nil {
+			return fmt.Errorf("failed to print results: %v", err)
+		}
+		return newRotationError(all)
+	}
+	return nil
+}
+
+// parseDryRun parses the value of the --dry-run flag, which is either a boolean,
+// accepted in any form strconv.ParseBool accepts as it was before the deep mode was added, or deep.
+func parseDryRun(value string) (dryRun bool, deep bool, err error) {
+	switch {
+	case value == "":
+		return false, false, nil
+	case strings.EqualFold(value, dryRunDeep):
+		return true, true, nil
+	}
+	dryRun, err = strconv.ParseBool(value)
+	if err != nil {
+		return false, false, fmt.Errorf("invalid --dry-run value %q, expected true, false or deep", value)
+	}
+	return dryRun, false, nil
+}
+
+// splitDryRunArg separates the value of --dry-run given as its own argument, e.g. --dry-run false,
+// which pflag leaves among the positional arguments, from the path to the configuration file.
+func splitDryRunArg(set bool, value string, args []string) (string, []string) {
+	if !set || value != "true" || len(args) != 2 {
+		return value, args
+	}
+	for i, arg := range args {
+		if _, _, err := parseDryRun(arg); err == nil && arg != "" {
+			return arg, append(args[:i:i], args[i+1:]...)
+		}
+	}
+	return value, args
+}
+
+// preflight checks every destination of the secrets that have pending destinations, running up to parallel checks at a time,
+// and fails if any of them cannot be updated.
+// The destinations already updated by an interrupted rotation are checked too,
+// since they are updated again if the value differs from the one it used.
+func preflight(ctx context.Context, clients *github.Clients, secrets []config.Secret, pending [][]config.DestinationWrapper, parallel int) error {
+	var toCheck []config.Secret
+	checked := 0
+	for i, secret := range secrets {
+		if len(pending[i]) == 0 {
+			continue
+		}
+		toCheck = append(toCheck, secret)
+		checked += len(secret.Destinations)
 	}
+
+	if err := newCheckError(checkSecrets(ctx, clients, toCheck, parallel)); err != nil {
+		return fmt.Errorf("nothing was rotated, %w", err)
+	}
+	fmt.Printf("Checked %d %s\n", checked, plural(checked, "destination", "destinations"))
 	return nil
 }
 
+// resolveSecretValue returns the value of the secret, checked against its validation rules.
+// In a shallow dry run, a command source is not run and the value is empty.
+func resolveSecretValue(secret config.Secret, opts *rotateOptions) (string, error) {
+	if skipsCommand(secret, opts) {
+		fmt.Printf("[Dry Run] Would run %q to read the value for %s\n", strings.Join(sourceCommand(secret), " "), secret.Name)
+		return "", nil
+	}
+
+	secretValue, err := readSecretValue(secret, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if secret.Validation != nil {
+		if err := secret.Validation.Check(secretValue); err != nil {
+			return "", fmt.Errorf("invalid value for %s: %w", secret.Name, err)
+		}
+	}
+	return secretValue, nil
+}
+
+// sourceCommand returns the command that reads the value of the secret, or nil if it has no command source.
+func sourceCommand(secret config.Secret) []string {
+	if secret.Source == nil || len(secret.Source.Command) == 0 {
+		return nil
+	}
+	return secret.Source.Command
+}
+
+// skipsCommand reports whether reading the value of the secret would run a command during a shallow dry run,
+// which only prints the command since it may create a real credential upstream.
+func skipsCommand(secret config.Secret, opts *rotateOptions) bool {
+	return opts.dryRun && !opts.deepDryRun && sourceCommand(secret) != nil
+}
+
+// readSecretValue returns the value of the secret from its generator or source.
+// Otherwise, the user is prompted for the value, unless running in non-interactive mode.
+func readSecretValue(secret config.Secret, opts *rotateOptions) (string, error) {
+	if generator := secret.Generator(); generator != nil {
+		// Generate the value instead of prompting for it, e.g. for deploy keys.
+		secretValue, err := generator.GenerateSecret()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate value for %s: %v", secret.Name, err)
+		}
+		fmt.Printf("Generated a new value for %s\n", secret.Name)
+		return secretValue, nil
+	}
+
+	if secret.Source != nil {
+		secretValue, err := secret.Source.Read(opts.stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value for %s: %v", secret.Name, err)
+		}
+		return secretValue, nil
+	}
+
+	if opts.nonInteractive {
+		return "", fmt.Errorf("no value for %s: a generate or source block is required in non-interactive mode", secret.Name)
+	}
+
+	// Prompt the user to enter the value for the secret.
+	title := fmt.Sprintf("%s: %s", secret.Name, secret.Description)
+	prompt := secretPrompt
+	if secret.Multiline || opts.multiline {
+		prompt = multilineSecretPrompt
+	}
+	if secret.ConfirmInput || opts.confirmInput {
+		prompt = doubleEntryPrompt(prompt)
+	}
+	secretValue, err := prompt(title)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input for %s: %v", secret.Name, err)
+	}
+	return secretValue, nil
+}
+
+// doubleEntryPrompt wraps a prompt to ask for the value a second time.
+// Since the input is masked, this catches typos that would otherwise go unnoticed.
+func doubleEntryPrompt(prompt func(title string) (string, error)) func(title string) (string, error) {
+	return func(title string) (string, error) {
+		value, err := prompt(title)
+		if err != nil {
+			return "", err
+		}
+		confirmation, err := prompt("Confirm " + title)
+		if err != nil {
+			return "", err
+		}
+		if confirmation != value {
+			return "", fmt.Errorf("the values entered do not match")
+		}
+		return value, nil
+	}
+}
+
+// fingerprint returns a short SHA-256 prefix of the value,
+// which can be compared against the source system without revealing the value.
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 func init() {
-	rotateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print out the changes that would be made without actually making them")
+	rotateCmd.Flags().StringVar(&dryRun, "dry-run", "", "Print out the changes that would be made without actually making them, or with --dry-run=deep, also verify every update up to the final write")
+	rotateCmd.Flags().Lookup("dry-run").NoOptDefVal = "true"
+	rotateCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt, reading every value from its generate or source block")
+	rotateCmd.Flags().IntVar(&parallel, "parallel", 1, "Number of destinations to update concurrently")
+	rotateCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Attempt every destination even if some fail, then summarize the failures")
+	rotateCmd.Flags().BoolVar(&resume, "resume", false, "Resume an interrupted rotation, updating only the destinations it did not complete")
+	rotateCmd.Flags().BoolVar(&skipCheck, "skip-check", false, "Skip checking that every destination exists and can be updated before rotating")
+	rotateCmd.Flags().BoolVar(&runCommands, "run-commands", false, "Run the command sources during a deep dry run, which may create real credentials upstream")
+	rotateCmd.Flags().BoolVar(&confirmInput, "confirm-input", false, "Prompt for every value twice and stop if the entries do not match")
+	rotateCmd.Flags().BoolVar(&multiline, "multiline", false, "Prompt for every value with a multi-line input, e.g. for PEM keys or JSON credentials")
 }
 
 func secretPrompt(title string) (string, error) {
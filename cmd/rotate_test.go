@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/generate"
+	"github.com/lucasmelin/key-rotator/validate"
+)
+
+func Test_resolveSecretValue(t *testing.T) {
+	t.Setenv("KEY_ROTATOR_TEST_SECRET", "env-value")
+
+	tests := []struct {
+		name        string
+		secret      config.Secret
+		stdin       string
+		expectError bool
+		want        string
+	}{
+		{
+			name:   "environment variable source",
+			secret: config.Secret{Name: "test", Source: &config.Source{Env: "KEY_ROTATOR_TEST_SECRET"}},
+			want:   "env-value",
+		},
+		{
+			name:   "stdin source",
+			secret: config.Secret{Name: "test", Source: &config.Source{Stdin: true}},
+			stdin:  "stdin-value\n",
+			want:   "stdin-value\n",
+		},
+		{
+			name:        "missing environment variable",
+			secret:      config.Secret{Name: "test", Source: &config.Source{Env: "KEY_ROTATOR_TEST_MISSING"}},
+			expectError: true,
+		},
+		{
+			name: "value fails validation",
+			secret: config.Secret{
+				Name:       "test",
+				Source:     &config.Source{Stdin: true},
+				Validation: &validate.Rules{ForbidSurroundingWhitespace: true},
+			},
+			stdin:       "stdin-value\n",
+			expectError: true,
+		},
+		{
+			name:        "no generator or source",
+			secret:      config.Secret{Name: "test"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &rotateOptions{
+				nonInteractive: true,
+				stdin:          strings.NewReader(tt.stdin),
+			}
+			got, err := resolveSecretValue(tt.secret, opts)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("resolveSecretValue() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSecretValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveSecretValue_Generator(t *testing.T) {
+	secret := config.Secret{Name: "test", Generate: &generate.Options{Type: generate.TypeHex, Length: 8}}
+	got, err := resolveSecretValue(secret, &rotateOptions{nonInteractive: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got) != 16 {
+		t.Errorf("Expected 16 hex characters, got %q", got)
+	}
+}
+
+func Test_resolveSecretValue_DryRunCommand(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	secret := config.Secret{Name: "test", Source: &config.Source{Command: []string{"touch", marker}}}
+
+	got, err := resolveSecretValue(secret, &rotateOptions{dryRun: true, nonInteractive: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "" {
+		t.Errorf("Expected no value, got %q", got)
+	}
+	if _, err := os.Stat(marker); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the command not to run during a dry run, got %v", err)
+	}
+}
+
+func Test_runRotate_DeepDryRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	yamlFile := filepath.Join(dir, "key.yaml")
+	content := `
+secrets:
+  - name: test-secret
+    description: A test secret
+    source:
+      command: ["touch", "` + marker + `"]
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`
+	if err := os.WriteFile(yamlFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err := runRotate(&rotateOptions{dryRun: true, deepDryRun: true, nonInteractive: true, parallel: 1, yamlFile: yamlFile})
+	if err == nil || !strings.Contains(err.Error(), "--run-commands") {
+		t.Errorf("Expected an error asking for --run-commands, got %v", err)
+	}
+	if _, err := os.Stat(marker); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the command not to run during a deep dry run, got %v", err)
+	}
+}
+
+func Test_doubleEntryPrompt(t *testing.T) {
+	tests := []struct {
+		name        string
+		entries     []string
+		expectError bool
+		want        string
+	}{
+		{
+			name:    "matching entries",
+			entries: []string{"secret", "secret"},
+			want:    "secret",
+		},
+		{
+			name:        "mismatched entries",
+			entries:     []string{"secret", "secert"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var titles []string
+			prompt := func(title string) (string, error) {
+				titles = append(titles, title)
+				return tt.entries[len(titles)-1], nil
+			}
+
+			got, err := doubleEntryPrompt(prompt)("SECRET")
+			if (err != nil) != tt.expectError {
+				t.Fatalf("doubleEntryPrompt() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.want {
+				t.Errorf("doubleEntryPrompt() = %q, want %q", got, tt.want)
+			}
+			if len(titles) != 2 || titles[1] != "Confirm SECRET" {
+				t.Errorf("Expected to be prompted twice, got %q", titles)
+			}
+		})
+	}
+}
+
+func Test_parseDryRun(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantDryRun  bool
+		wantDeep    bool
+		expectError bool
+	}{
+		{value: ""},
+		{value: "true", wantDryRun: true},
+		{value: "1", wantDryRun: true},
+		{value: "t", wantDryRun: true},
+		{value: "TRUE", wantDryRun: true},
+		{value: "false"},
+		{value: "0"},
+		{value: "deep", wantDryRun: true, wantDeep: true},
+		{value: "Deep", wantDryRun: true, wantDeep: true},
+		{value: "shallow", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			dryRun, deep, err := parseDryRun(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseDryRun() error = %v, expectError %v", err, tt.expectError)
+			}
+			if dryRun != tt.wantDryRun || deep != tt.wantDeep {
+				t.Errorf("parseDryRun() = %v, %v, want %v, %v", dryRun, deep, tt.wantDryRun, tt.wantDeep)
+			}
+		})
+	}
+}
+
+func Test_splitDryRunArg(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       bool
+		value     string
+		args      []string
+		wantValue string
+		wantArgs  []string
+	}{
+		{name: "not set", value: "", args: []string{"key.yaml"}, wantValue: "", wantArgs: []string{"key.yaml"}},
+		{name: "without a value", set: true, value: "true", args: []string{"key.yaml"}, wantValue: "true", wantArgs: []string{"key.yaml"}},
+		{name: "value before the file", set: true, value: "true", args: []string{"false", "key.yaml"}, wantValue: "false", wantArgs: []string{"key.yaml"}},
+		{name: "value after the file", set: true, value: "true", args: []string{"key.yaml", "deep"}, wantValue: "deep", wantArgs: []string{"key.yaml"}},
+		{name: "two files", set: true, value: "true", args: []string{"a.yaml", "b.yaml"}, wantValue: "true", wantArgs: []string{"a.yaml", "b.yaml"}},
+		{name: "value given with =", set: true, value: "false", args: []string{"true", "key.yaml"}, wantValue: "false", wantArgs: []string{"true", "key.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, args := splitDryRunArg(tt.set, tt.value, tt.args)
+			if value != tt.wantValue || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("splitDryRunArg() = %q, %v, want %q, %v", value, args, tt.wantValue, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_fingerprint(t *testing.T) {
+	// SHA-256 of "secret" is 2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b.
+	if got := fingerprint("secret"); got != "2bb80d537b1d" {
+		t.Errorf("fingerprint() = %q, want %q", got, "2bb80d537b1d")
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+	"github.com/spf13/cobra"
+)
+
+// States of a destination in the status report.
+const (
+	stateOK      = "ok"
+	stateMissing = "missing"
+	stateStale   = "stale"
+	stateError   = "error"
+	stateUnknown = "unknown"
+)
+
+var driftTolerance time.Duration
+
+var statusCmd = &cobra.Command{
+	Use:     "status <path to YAML config file>",
+	Short:   "Show whether each destination has its secret and when it was last updated, without rotating anything",
+	Args:    cobra.ExactArgs(1),
+	GroupID: "core-commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if driftTolerance < 0 {
+			return fmt.Errorf("--tolerance must not be negative, got %s", driftTolerance)
+		}
+		return runStatus(args[0], driftTolerance)
+	},
+}
+
+// destinationStatus is the state of the secret in a single destination.
+type destinationStatus struct {
+	destination config.DestinationWrapper
+	status      github.SecretStatus
+	err         error
+	// unsupported is set for destinations that cannot report their status.
+	unsupported bool
+}
+
+// secretStatuses holds the status of every destination of one secret.
+type secretStatuses struct {
+	secret       string
+	destinations []destinationStatus
+}
+
+func runStatus(yamlFile string, tolerance time.Duration) error {
+	cfg, err := config.ParseFile(yamlFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %v", err)
+	}
+
+	clients, err := authenticate(cfg, true)
+	if err != nil {
+		return err
+	}
+
+	all := fetchStatuses(context.Background(), clients, cfg.Secrets)
+	fmt.Println()
+	if err := printStatuses(os.Stdout, all, tolerance); err != nil {
+		return fmt.Errorf("failed to print status: %v", err)
+	}
+	return driftError(all, tolerance)
+}
+
+// fetchStatuses gets the status of every destination of the secrets.
+func fetchStatuses(ctx context.Context, clients *github.Clients, secrets []config.Secret) []secretStatuses {
+	all := make([]secretStatuses, len(secrets))
+	for i, secret := range secrets {
+		all[i].secret = secret.Name
+		all[i].destinations = make([]destinationStatus, len(secret.Destinations))
+		for j, d := range secret.Destinations {
+			result := &all[i].destinations[j]
+			result.destination = d
+
+			reporter, ok := d.Destination.(config.StatusReporter)
+			if !ok {
+				result.unsupported = true
+				continue
+			}
+			client, err := destinationClient(ctx, clients, d)
+			if err == nil {
+				result.status, err = reporter.Status(ctx, client)
+			}
+			result.err = err
+		}
+	}
+	return all
+}
+
+// lastUpdated returns the most recent update of any destination of the secret,
+// or the zero time if none of them report one.
+func (s secretStatuses) lastUpdated() time.Time {
+	var latest time.Time
+	for _, d := range s.destinations {
+		if d.err == nil && d.status.UpdatedAt.After(latest) {
+			latest = d.status.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// state describes the destination compared to the most recent update of its secret.
+// A destination updated more than tolerance before it was missed by a previous rotation.
+func (d destinationStatus) state(latest time.Time, tolerance time.Duration) string {
+	switch {
+	case d.err != nil:
+		return stateError
+	case d.unsupported:
+		return stateUnknown
+	case !d.status.Exists:
+		return stateMissing
+	case !d.status.UpdatedAt.IsZero() && latest.Sub(d.status.UpdatedAt) > tolerance:
+		return stateStale
+	}
+	return stateOK
+}
+
+// printStatuses writes a table of the state and last update of every destination,
+// followed by the errors of those whose status could not be read.
+func printStatuses(w io.Writer, all []secretStatuses, tolerance time.Duration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECRET\tDESTINATION\tSTATE\tUPDATED")
+	var failures []destinationFailure
+	for _, s := range all {
+		latest := s.lastUpdated()
+		for _, d := range s.destinations {
+			updated := "-"
+			if !d.status.UpdatedAt.IsZero() {
+				updated = d.status.UpdatedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.secret, d.destination.GetDescription(), d.state(latest, tolerance), updated)
+			if d.err != nil {
+				failures = append(failures, destinationFailure{Secret: s.secret, Destination: d.destination.GetDescription(), Err: d.err})
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		fmt.Fprintf(w, "\nThe status of %d %s could not be read:%s\n", len(failures), plural(len(failures), "destination", "destinations"), listFailures(failures))
+	}
+	return nil
+}
+
+// driftError returns an error counting the destinations that are missing, stale or could not be read, or nil if there are none.
+func driftError(all []secretStatuses, tolerance time.Duration) error {
+	counts := map[string]int{}
+	total := 0
+	for _, s := range all {
+		latest := s.lastUpdated()
+		for _, d := range s.destinations {
+			counts[d.state(latest, tolerance)]++
+			total++
+		}
+	}
+
+	drifted := counts[stateMissing] + counts[stateStale] + counts[stateError]
+	if drifted == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d destinations need attention: %d missing, %d stale, %d could not be read", drifted, total, counts[stateMissing], counts[stateStale], counts[stateError])
+}
+
+func init() {
+	statusCmd.Flags().DurationVar(&driftTolerance, "tolerance", time.Hour, "How much older than the most recent destination of its secret a destination can be before it is reported as stale")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+func Test_printStatuses(t *testing.T) {
+	rotated := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := &concurrencyTracker{}
+	destination := func(name string) config.DestinationWrapper {
+		return config.DestinationWrapper{Destination: &fakeDestination{name: name, tracker: tracker}}
+	}
+
+	all := []secretStatuses{
+		{
+			secret: "api-key",
+			destinations: []destinationStatus{
+				{destination: destination("repo-a"), status: github.SecretStatus{Exists: true, UpdatedAt: rotated}},
+				{destination: destination("repo-b"), status: github.SecretStatus{Exists: true, UpdatedAt: rotated.Add(-10 * time.Minute)}},
+				{destination: destination("repo-c"), status: github.SecretStatus{Exists: true, UpdatedAt: rotated.Add(-48 * time.Hour)}},
+				{destination: destination("repo-d")},
+			},
+		},
+		{
+			secret: "token",
+			destinations: []destinationStatus{
+				{destination: destination("org"), err: errors.New("permission denied")},
+				{destination: destination("other"), unsupported: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printStatuses(&buf, all, time.Hour); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "SECRET   DESTINATION  STATE    UPDATED\n" +
+		"api-key  repo-a       ok       2025-03-01T12:00:00Z\n" +
+		"api-key  repo-b       ok       2025-03-01T11:50:00Z\n" +
+		"api-key  repo-c       stale    2025-02-27T12:00:00Z\n" +
+		"api-key  repo-d       missing  -\n" +
+		"token    org          error    -\n" +
+		"token    other        unknown  -\n" +
+		"\nThe status of 1 destination could not be read:\n- org (token): permission denied\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	wantErr := "3 of 6 destinations need attention: 1 missing, 1 stale, 1 could not be read"
+	if err := driftError(all, time.Hour); err == nil || err.Error() != wantErr {
+		t.Errorf("Expected error %q, got %v", wantErr, err)
+	}
+	if err := driftError(all[:0], time.Hour); err != nil {
+		t.Errorf("Expected no error without destinations, got %v", err)
+	}
+}
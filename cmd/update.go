@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+// destinationResult is the outcome of updating a single destination.
+type destinationResult struct {
+	destination config.DestinationWrapper
+	err         error
+	// skipped is set when the update was never attempted because another update failed.
+	skipped bool
+}
+
+// secretResults holds the results of updating the destinations of one secret.
+type secretResults struct {
+	secret  string
+	results []destinationResult
+}
+
+// updateDestinations updates every destination with the secret value, running up to opts.parallel updates at a time,
+// and calls onUpdated after each successful update.
+// With a deep dry run, every update is verified up to the request that would write the value, which is not sent.
+// Unless opts.keepGoing is set, no further updates are started once one fails, but those already in flight are allowed to finish.
+// The results are returned in the same order as the destinations.
+func updateDestinations(ctx context.Context, clients *github.Clients, destinations []config.DestinationWrapper, secretValue string, opts *rotateOptions, onUpdated func(config.DestinationWrapper)) []destinationResult {
+	results := make([]destinationResult, len(destinations))
+	semaphore := make(chan struct{}, max(opts.parallel, 1))
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+
+	for i, d := range destinations {
+		results[i].destination = d
+
+		semaphore <- struct{}{}
+		if failed.Load() && !opts.keepGoing {
+			<-semaphore
+			results[i].skipped = true
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			err := updateDestination(ctx, clients, d, secretValue, opts.deepDryRun)
+			if err != nil {
+				results[i].err = err
+				failed.Store(true)
+				fmt.Printf("Failed to update %s: %v\n", d.GetDescription(), err)
+				return
+			}
+			if opts.deepDryRun {
+				fmt.Println("[Dry Run] Verified", d.GetDescription())
+			} else {
+				fmt.Println("Updated", d.GetDescription())
+			}
+			onUpdated(d)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// updateDestination updates the destination using the client for its host and owner.
+// With dryRun set, the client validates the final write instead of sending it.
+func updateDestination(ctx context.Context, clients *github.Clients, d config.DestinationWrapper, secretValue string, dryRun bool) error {
+	client, err := destinationClient(ctx, clients, d)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		client = client.DryRun()
+	}
+	return d.Destination.UpdateSecret(ctx, client, secretValue)
+}
+
+// destinationClient returns the client for the host and owner of the destination.
+func destinationClient(ctx context.Context, clients *github.Clients, d config.DestinationWrapper) (github.Client, error) {
+	var owner string
+	if owned, ok := d.Destination.(config.Owned); ok {
+		owner = owned.Owner()
+	}
+	return clients.Get(ctx, d.Host, owner)
+}
+
+// firstError returns the first error in the results, if any.
+func firstError(results []destinationResult) error {
+	for _, result := range results {
+		if result.err != nil {
+			return result.err
+		}
+	}
+	return nil
+}
+
+// printResults writes a table of the outcome of every attempted destination.
+func printResults(w io.Writer, all []secretResults) error {
+	return printTable(w, all, func(result destinationResult) string {
+		switch {
+		case result.skipped:
+			return "skipped"
+		case result.err != nil:
+			return "failed"
+		}
+		return "updated"
+	})
+}
+
+// printDryRunResults writes a table of the outcome of verifying every destination in a deep dry run.
+func printDryRunResults(w io.Writer, all []secretResults) error {
+	return printTable(w, all, func(result destinationResult) string {
+		switch {
+		case result.skipped:
+			return "skipped"
+		case result.err != nil:
+			return "failed"
+		}
+		return "verified"
+	})
+}
+
+// printTable writes a table of the destinations of every secret, described by status.
+func printTable(w io.Writer, all []secretResults, status func(destinationResult) string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECRET\tDESTINATION\tRESULT")
+	for _, s := range all {
+		for _, result := range s.results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", s.secret, result.destination.GetDescription(), status(result))
+		}
+	}
+	return tw.Flush()
+}
+
+// destinationFailure is a destination that could not be updated.
+type destinationFailure struct {
+	Secret      string
+	Destination string
+	Err         error
+}
+
+// rotationError lists every destination that could not be updated during a rotation.
+type rotationError struct {
+	Failures []destinationFailure
+}
+
+// newRotationError returns a rotationError for the failed destinations, or nil if none failed.
+func newRotationError(all []secretResults) error {
+	failures := destinationFailures(all)
+	if len(failures) == 0 {
+		return nil
+	}
+	return &rotationError{Failures: failures}
+}
+
+// destinationFailures returns the destinations of every secret that failed.
+func destinationFailures(all []secretResults) []destinationFailure {
+	var failures []destinationFailure
+	for _, s := range all {
+		for _, result := range s.results {
+			if result.err != nil {
+				failures = append(failures, destinationFailure{
+					Secret:      s.secret,
+					Destination: result.destination.GetDescription(),
+					Err:         result.err,
+				})
+			}
+		}
+	}
+	return failures
+}
+
+func (e *rotationError) Error() string {
+	return fmt.Sprintf("failed to update %d %s:%s", len(e.Failures), plural(len(e.Failures), "destination", "destinations"), listFailures(e.Failures))
+}
+
+func (e *rotationError) Unwrap() []error {
+	return failureErrors(e.Failures)
+}
+
+// listFailures lists each failure on its own line.
+func listFailures(failures []destinationFailure) string {
+	var b strings.Builder
+	for _, f := range failures {
+		fmt.Fprintf(&b, "\n- %s (%s): %v", f.Destination, f.Secret, f.Err)
+	}
+	return b.String()
+}
+
+// failureErrors returns the error of each failure.
+func failureErrors(failures []destinationFailure) []error {
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucasmelin/key-rotator/config"
+	"github.com/lucasmelin/key-rotator/github"
+)
+
+// fakeDestination records updates and tracks how many run at the same time.
+type fakeDestination struct {
+	name    string
+	err     error
+	tracker *concurrencyTracker
+	updated bool
+}
+
+type concurrencyTracker struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (d *fakeDestination) UpdateSecret(_ context.Context, _ github.Client, _ string) error {
+	d.tracker.mu.Lock()
+	d.tracker.current++
+	d.tracker.peak = max(d.tracker.peak, d.tracker.current)
+	d.tracker.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	d.tracker.mu.Lock()
+	d.tracker.current--
+	d.tracker.mu.Unlock()
+
+	d.updated = true
+	return d.err
+}
+
+func (d *fakeDestination) GetDescription() string {
+	return d.name
+}
+
+func Test_updateDestinations(t *testing.T) {
+	errUpdate := errors.New("update failed")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	clients, err := github.NewClients([]string{""}, github.Auth{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		parallel    int
+		keepGoing   bool
+		errors      []error
+		wantPeak    int
+		wantSkipped []bool
+		wantErr     error
+	}{
+		{
+			name:        "sequential",
+			parallel:    1,
+			errors:      []error{nil, nil, nil, nil},
+			wantPeak:    1,
+			wantSkipped: []bool{false, false, false, false},
+		},
+		{
+			name:        "bounded parallelism",
+			parallel:    2,
+			errors:      []error{nil, nil, nil, nil, nil, nil},
+			wantPeak:    2,
+			wantSkipped: []bool{false, false, false, false, false, false},
+		},
+		{
+			name:        "parallelism larger than destinations",
+			parallel:    10,
+			errors:      []error{nil, nil, nil},
+			wantPeak:    3,
+			wantSkipped: []bool{false, false, false},
+		},
+		{
+			name:        "failure stops further updates",
+			parallel:    1,
+			errors:      []error{nil, errUpdate, nil},
+			wantPeak:    1,
+			wantSkipped: []bool{false, false, true},
+			wantErr:     errUpdate,
+		},
+		{
+			name:        "keep going after a failure",
+			parallel:    1,
+			keepGoing:   true,
+			errors:      []error{nil, errUpdate, nil},
+			wantPeak:    1,
+			wantSkipped: []bool{false, false, false},
+			wantErr:     errUpdate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &concurrencyTracker{}
+			var destinations []config.DestinationWrapper
+			var fakes []*fakeDestination
+			for i, err := range tt.errors {
+				fake := &fakeDestination{name: string(rune('a' + i)), err: err, tracker: tracker}
+				fakes = append(fakes, fake)
+				destinations = append(destinations, config.DestinationWrapper{Destination: fake})
+			}
+
+			opts := &rotateOptions{parallel: tt.parallel, keepGoing: tt.keepGoing}
+			var mu sync.Mutex
+			var updated []config.DestinationWrapper
+			results := updateDestinations(context.Background(), clients, destinations, "value", opts, func(d config.DestinationWrapper) {
+				mu.Lock()
+				defer mu.Unlock()
+				updated = append(updated, d)
+			})
+
+			if len(results) != len(destinations) {
+				t.Fatalf("Expected %d results, got %d", len(destinations), len(results))
+			}
+			for i, result := range results {
+				if result.destination.Destination != fakes[i] {
+					t.Errorf("Result %d is for %s, expected %s", i, result.destination.GetDescription(), fakes[i].name)
+				}
+				if result.skipped != tt.wantSkipped[i] {
+					t.Errorf("Result %d skipped = %v, expected %v", i, result.skipped, tt.wantSkipped[i])
+				}
+				if fakes[i].updated == result.skipped {
+					t.Errorf("Destination %d updated = %v, but skipped = %v", i, fakes[i].updated, result.skipped)
+				}
+			}
+			want := 0
+			for _, result := range results {
+				if !result.skipped && result.err == nil {
+					want++
+				}
+			}
+			if len(updated) != want {
+				t.Errorf("Expected %d updates to be reported, got %d", want, len(updated))
+			}
+			if tracker.peak != tt.wantPeak {
+				t.Errorf("Expected at most %d concurrent updates, got %d", tt.wantPeak, tracker.peak)
+			}
+			if err := firstError(results); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_rotationResults(t *testing.T) {
+	errUpdate := errors.New("update failed")
+	tracker := &concurrencyTracker{}
+	all := []secretResults{
+		{
+			secret: "api-key",
+			results: []destinationResult{
+				{destination: config.DestinationWrapper{Destination: &fakeDestination{name: "repo-a", tracker: tracker}}},
+				{destination: config.DestinationWrapper{Destination: &fakeDestination{name: "repo-b", tracker: tracker}}, err: errUpdate},
+			},
+		},
+		{
+			secret: "token",
+			results: []destinationResult{
+				{destination: config.DestinationWrapper{Destination: &fakeDestination{name: "org", tracker: tracker}}, err: errUpdate},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printResults(&buf, all); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wantTable := "SECRET   DESTINATION  RESULT\n" +
+		"api-key  repo-a       updated\n" +
+		"api-key  repo-b       failed\n" +
+		"token    org          failed\n"
+	if buf.String() != wantTable {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", wantTable, buf.String())
+	}
+
+	err := newRotationError(all)
+	var rotationErr *rotationError
+	if !errors.As(err, &rotationErr) {
+		t.Fatalf("Expected a rotationError, got %v", err)
+	}
+	if len(rotationErr.Failures) != 2 {
+		t.Errorf("Expected 2 failures, got %d", len(rotationErr.Failures))
+	}
+	if !errors.Is(err, errUpdate) {
+		t.Errorf("Expected error to wrap %v", errUpdate)
+	}
+	wantMessage := "failed to update 2 destinations:\n- repo-b (api-key): update failed\n- org (token): update failed"
+	if err.Error() != wantMessage {
+		t.Errorf("Expected error %q, got %q", wantMessage, err.Error())
+	}
+
+	if err := newRotationError(all[:0]); err != nil {
+		t.Errorf("Expected no error without failures, got %v", err)
+	}
+}
@@ -4,13 +4,26 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/lucasmelin/key-rotator/generate"
 	"github.com/lucasmelin/key-rotator/github"
+	"github.com/lucasmelin/key-rotator/validate"
 	"gopkg.in/yaml.v3"
 )
 
 // KeyConfig represents the structure of the YAML configuration file.
 type KeyConfig struct {
+	// Host or BaseURL select the GitHub Enterprise Server instance of destinations that do not set their own.
+	Host    string `yaml:"host"`
+	BaseURL string `yaml:"base_url"`
+	// GitHubApp authenticates as a GitHub App installation instead of with tokens.
+	GitHubApp *github.AppCredentials `yaml:"github_app"`
+	// TokenHelper is a command that prints the token of the host passed as its last argument.
+	TokenHelper []string `yaml:"token_helper"`
+	// MaxAge is how long secrets that do not set their own max_age may go without being rotated.
+	MaxAge  Duration `yaml:"max_age"`
 	Secrets []Secret `yaml:"secrets"`
 }
 
@@ -18,6 +31,12 @@ type KeyConfig struct {
 type Secret struct {
 	Name         string               `yaml:"name"`
 	Description  string               `yaml:"description"`
+	Generate     *generate.Options    `yaml:"generate"`
+	Source       *Source              `yaml:"source"`
+	Multiline    bool                 `yaml:"multiline"`
+	ConfirmInput bool                 `yaml:"confirm_input"`
+	Validation   *validate.Rules      `yaml:"validate"`
+	MaxAge       Duration             `yaml:"max_age"`
 	Destinations []DestinationWrapper `yaml:"destinations"`
 }
 
@@ -27,9 +46,70 @@ type Destination interface {
 	GetDescription() string
 }
 
+// Generator is implemented by anything that can generate the secret value,
+// such as a generate block or a deploy key destination.
+// The generated value is then written to every destination of the secret.
+type Generator interface {
+	GenerateSecret() (string, error)
+}
+
+// Owned is implemented by destinations that belong to a user or organization.
+// The owner selects the GitHub App installation used to update the destination.
+type Owned interface {
+	Owner() string
+}
+
+// Checker is implemented by destinations that can verify, without changing anything,
+// that they exist and that the client can read what updating them requires, such as their public key.
+type Checker interface {
+	Check(ctx context.Context, client github.Client) error
+}
+
+// Validator is implemented by destinations whose settings can be checked when the configuration is parsed,
+// so that an invalid destination fails before any value is requested.
+type Validator interface {
+	Validate() error
+}
+
+// Plaintext is implemented by destinations that store the value unencrypted, such as Actions variables,
+// and reports whether the destination opted in to holding a value that is also stored in encrypted secrets.
+type Plaintext interface {
+	AllowsPlaintext() bool
+}
+
+// StatusReporter is implemented by destinations that can report whether the secret exists
+// and when it was last updated, without changing anything.
+type StatusReporter interface {
+	Status(ctx context.Context, client github.Client) (github.SecretStatus, error)
+}
+
+// Generator returns what generates the value of the secret, if anything.
+func (s Secret) Generator() Generator {
+	if s.Generate != nil {
+		return *s.Generate
+	}
+	for _, d := range s.Destinations {
+		if generator, ok := d.Destination.(Generator); ok {
+			return generator
+		}
+	}
+	return nil
+}
+
 // DestinationWrapper wraps the Destination interface for custom unmarshaling.
 type DestinationWrapper struct {
 	Destination
+	// Host is the GitHub host of the destination, set from its host or base_url, or the global one.
+	// It is empty for github.com.
+	Host string
+}
+
+// GetDescription returns the destination description, including its host when it is not github.com.
+func (d DestinationWrapper) GetDescription() string {
+	if d.Host == "" {
+		return d.Destination.GetDescription()
+	}
+	return fmt.Sprintf("%s on %s", d.Destination.GetDescription(), d.Host)
 }
 
 // UnmarshalYAML custom unmarshaler for Destination.
@@ -62,11 +142,92 @@ func (d *DestinationWrapper) UnmarshalYAML(value *yaml.Node) error {
 			return err
 		}
 		d.Destination = dest
+	case github.TypeGitHubOrganization:
+		var dest github.OrganizationSecret
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubOrganizationDependabot:
+		var dest github.DependabotOrganizationSecret
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubRepositoryCodespaces:
+		var dest github.CodespacesRepositorySecret
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubOrganizationCodespaces:
+		var dest github.CodespacesOrganizationSecret
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubUserCodespaces:
+		var dest github.CodespacesUserSecret
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubRepositoryVariable:
+		var dest github.RepositoryVariable
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubRepositoryEnvironmentVariable:
+		var dest github.RepositoryEnvironmentVariable
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubOrganizationVariable:
+		var dest github.OrganizationVariable
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubRepositoryWebhook:
+		var dest github.RepositoryWebhook
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubOrganizationWebhook:
+		var dest github.OrganizationWebhook
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
+	case github.TypeGitHubDeployKey:
+		var dest github.DeployKey
+		if err := value.Decode(&dest); err != nil {
+			return err
+		}
+		d.Destination = dest
 	default:
 		return fmt.Errorf("unsupported destination type: %s", destType)
 	}
 
-	return nil
+	host, _ := raw["host"].(string)
+	baseURL, _ := raw["base_url"].(string)
+	var err error
+	d.Host, err = hostSetting(host, baseURL)
+	return err
+}
+
+// hostSetting returns the host selected by the host or base_url setting, at most one of which may be set.
+func hostSetting(host string, baseURL string) (string, error) {
+	if host != "" && baseURL != "" {
+		return "", fmt.Errorf("only one of host and base_url may be set")
+	}
+	if baseURL != "" {
+		return baseURL, nil
+	}
+	return host, nil
 }
 
 // ParseFile reads and parses the YAML configuration file.
@@ -82,5 +243,120 @@ func ParseFile(yamlFile string) (KeyConfig, error) {
 	if err := decoder.Decode(&config); err != nil {
 		return KeyConfig{}, fmt.Errorf("failed to decode %s: %v", yamlFile, err)
 	}
+	if err := config.validate(); err != nil {
+		return KeyConfig{}, fmt.Errorf("invalid configuration in %s: %v", yamlFile, err)
+	}
+
+	// Destinations without their own host use the global one.
+	host, err := hostSetting(config.Host, config.BaseURL)
+	if err != nil {
+		return KeyConfig{}, fmt.Errorf("invalid configuration in %s: %v", yamlFile, err)
+	}
+	for i := range config.Secrets {
+		for j := range config.Secrets[i].Destinations {
+			if config.Secrets[i].Destinations[j].Host == "" {
+				config.Secrets[i].Destinations[j].Host = host
+			}
+		}
+	}
 	return config, nil
 }
+
+// validate checks the configuration for conflicts that cannot be caught while decoding.
+func (c KeyConfig) validate() error {
+	if c.GitHubApp != nil {
+		if err := c.GitHubApp.Validate(); err != nil {
+			return fmt.Errorf("github_app: %v", err)
+		}
+	}
+
+	stdinSecrets := 0
+	for _, secret := range c.Secrets {
+		generators := 0
+		if secret.Generate != nil {
+			if err := secret.Generate.Validate(); err != nil {
+				return fmt.Errorf("secret %s: %v", secret.Name, err)
+			}
+			generators++
+		}
+		var encrypted int
+		var plaintext []string
+		for _, d := range secret.Destinations {
+			if _, ok := d.Destination.(Generator); ok {
+				generators++
+			}
+			if p, ok := d.Destination.(Plaintext); !ok {
+				encrypted++
+			} else if !p.AllowsPlaintext() {
+				plaintext = append(plaintext, d.GetDescription())
+			}
+			if validator, ok := d.Destination.(Validator); ok {
+				if err := validator.Validate(); err != nil {
+					return fmt.Errorf("secret %s: %s: %v", secret.Name, d.GetDescription(), err)
+				}
+			}
+		}
+		// A copy-pasted variable destination would publish the value to anyone who can read the repository.
+		if encrypted > 0 && len(plaintext) > 0 {
+			return fmt.Errorf("secret %s is written to encrypted secrets and to %s, which stores it in plaintext; set plaintext: true on the variable to allow it", secret.Name, strings.Join(plaintext, ", "))
+		}
+		if generators > 1 {
+			return fmt.Errorf("secret %s has %d generators for its value, at most one is allowed", secret.Name, generators)
+		}
+
+		if secret.Validation != nil {
+			if err := secret.Validation.Validate(); err != nil {
+				return fmt.Errorf("secret %s: %v", secret.Name, err)
+			}
+		}
+
+		if secret.Source != nil {
+			if err := secret.Source.validate(); err != nil {
+				return fmt.Errorf("secret %s: %v", secret.Name, err)
+			}
+			if generators > 0 {
+				return fmt.Errorf("secret %s cannot both generate its value and read it from a source", secret.Name)
+			}
+			if secret.Source.Stdin {
+				stdinSecrets++
+			}
+		}
+	}
+	if stdinSecrets > 1 {
+		return fmt.Errorf("%d secrets read their value from stdin, at most one is allowed", stdinSecrets)
+	}
+	return nil
+}
+
+// SecretMaxAge returns how long the secret may go without being rotated, or zero if it has no limit.
+func (c KeyConfig) SecretMaxAge(secret Secret) time.Duration {
+	if secret.MaxAge != 0 {
+		return time.Duration(secret.MaxAge)
+	}
+	return time.Duration(c.MaxAge)
+}
+
+// UsesStdin reports whether any secret reads its value from stdin.
+func (c KeyConfig) UsesStdin() bool {
+	for _, secret := range c.Secrets {
+		if secret.Source != nil && secret.Source.Stdin {
+			return true
+		}
+	}
+	return false
+}
+
+// Hosts returns the GitHub hosts used by the destinations, in the order they first appear.
+func (c KeyConfig) Hosts() []string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, secret := range c.Secrets {
+		for _, d := range secret.Destinations {
+			if !seen[d.Host] {
+				seen[d.Host] = true
+				hosts = append(hosts, d.Host)
+			}
+		}
+	}
+	return hosts
+}
@@ -4,9 +4,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/lucasmelin/key-rotator/generate"
 	"github.com/lucasmelin/key-rotator/github"
+	"github.com/lucasmelin/key-rotator/validate"
 	"gopkg.in/yaml.v3"
 )
 
@@ -106,6 +109,354 @@ secrets:
 				},
 			},
 		},
+		{
+			name: "GitHub organization secret",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-organization
+        org: owner
+        name: TEST_SECRET
+        visibility: selected
+        selected_repositories:
+          - repo
+          - other/repo
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.OrganizationSecret{
+									Org:                  "owner",
+									Name:                 "TEST_SECRET",
+									Visibility:           "selected",
+									SelectedRepositories: []string{"repo", "other/repo"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "GitHub organization dependabot secret",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-organization-dependabot
+        org: owner
+        name: TEST_SECRET
+        visibility: all
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.DependabotOrganizationSecret{
+									Org:        "owner",
+									Name:       "TEST_SECRET",
+									Visibility: "all",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Secret with generator",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    generate:
+      type: password
+      length: 64
+      charset: symbols
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Generate: &generate.Options{
+							Type:    "password",
+							Length:  64,
+							Charset: "symbols",
+						},
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.RepositorySecret{
+									Repo: "owner/repo",
+									Name: "TEST_SECRET",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid generator",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    generate:
+      type: unknown
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expectError: true,
+		},
+		{
+			name: "Organization secret with an invalid visibility",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-organization
+        org: org
+        name: TEST_SECRET
+        visibility: public
+`,
+			expectError: true,
+		},
+		{
+			name: "Organization variable with selected repositories and all visibility",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-organization-variable
+        org: org
+        name: TEST_VARIABLE
+        visibility: all
+        selected_repositories: [repo]
+`,
+			expectError: true,
+		},
+		{
+			name: "Secret and variable without plaintext",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+      - type: github-repository-variable
+        repo: owner/repo
+        name: TEST_VARIABLE
+`,
+			expectError: true,
+		},
+		{
+			name: "Secret and variable with plaintext",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+      - type: github-repository-variable
+        repo: owner/repo
+        name: TEST_VARIABLE
+        plaintext: true
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.RepositorySecret{
+									Repo: "owner/repo",
+									Name: "TEST_SECRET",
+								},
+							},
+							{
+								Destination: github.RepositoryVariable{
+									Repo:      "owner/repo",
+									Name:      "TEST_VARIABLE",
+									Plaintext: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Generator and deploy key",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    generate:
+      type: ed25519
+    destinations:
+      - type: github-deploy-key
+        repo: owner/repo
+        title: ci
+`,
+			expectError: true,
+		},
+		{
+			name: "Secret with source",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    source:
+      env: TEST_SECRET
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Source:      &Source{Env: "TEST_SECRET"},
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.RepositorySecret{
+									Repo: "owner/repo",
+									Name: "TEST_SECRET",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Source with multiple values",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    source:
+      env: TEST_SECRET
+      file: secret.txt
+    destinations: []
+`,
+			expectError: true,
+		},
+		{
+			name: "Source and generator",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    source: stdin
+    generate:
+      type: uuid
+    destinations: []
+`,
+			expectError: true,
+		},
+		{
+			name: "Multiple stdin sources",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    source: stdin
+    destinations: []
+  - name: other-secret
+    description: Another test secret
+    source: stdin
+    destinations: []
+`,
+			expectError: true,
+		},
+		{
+			name: "Secret with validation rules",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    validate:
+      min_length: 10
+      format: ssh-private-key
+      forbid_surrounding_whitespace: true
+    destinations: []
+`,
+			expectError: false,
+			expected: KeyConfig{
+				Secrets: []Secret{
+					{
+						Name:        "test-secret",
+						Description: "A test secret",
+						Validation: &validate.Rules{
+							MinLength:                   10,
+							Format:                      "ssh-private-key",
+							ForbidSurroundingWhitespace: true,
+						},
+						Destinations: []DestinationWrapper{},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid validation rules",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    validate:
+      regex: "("
+    destinations: []
+`,
+			expectError: true,
+		},
+		{
+			name: "Multiple generating destinations",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    description: A test secret
+    destinations:
+      - type: github-deploy-key
+        repo: owner/repo
+        title: ci
+      - type: github-deploy-key
+        repo: owner/other
+        title: ci
+`,
+			expectError: true,
+		},
 		{
 			name: "Invalid secret type",
 			yamlContent: `
@@ -115,6 +466,144 @@ secrets:
     destinations:
       - type: invalid-type
         description: Invalid type secret
+`,
+			expectError: true,
+		},
+		{
+			name: "Global host with a destination base URL",
+			yamlContent: `
+host: github.example.com
+secrets:
+  - name: test-secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+        base_url: https://ghes.internal/api/v3/
+`,
+			expected: KeyConfig{
+				Host: "github.example.com",
+				Secrets: []Secret{
+					{
+						Name: "test-secret",
+						Destinations: []DestinationWrapper{
+							{
+								Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"},
+								Host:        "github.example.com",
+							},
+							{
+								Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"},
+								Host:        "https://ghes.internal/api/v3/",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Destination with both host and base URL",
+			yamlContent: `
+secrets:
+  - name: test-secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+        host: github.example.com
+        base_url: https://github.example.com/api/v3/
+`,
+			expectError: true,
+		},
+		{
+			name: "GitHub App authentication",
+			yamlContent: `
+github_app:
+  app_id: 12345
+  private_key_file: app.pem
+secrets:
+  - name: test-secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expected: KeyConfig{
+				GitHubApp: &github.AppCredentials{AppID: 12345, PrivateKeyFile: "app.pem"},
+				Secrets: []Secret{
+					{
+						Name: "test-secret",
+						Destinations: []DestinationWrapper{
+							{Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Max age",
+			yamlContent: `
+max_age: 90d
+secrets:
+  - name: test-secret
+    max_age: 1d12h
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expected: KeyConfig{
+				MaxAge: Duration(90 * 24 * time.Hour),
+				Secrets: []Secret{
+					{
+						Name:   "test-secret",
+						MaxAge: Duration(36 * time.Hour),
+						Destinations: []DestinationWrapper{
+							{Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid max age",
+			yamlContent: `
+max_age: 90 days
+secrets: []
+`,
+			expectError: true,
+		},
+		{
+			name: "Token helper",
+			yamlContent: `
+token_helper: [pass, show]
+secrets:
+  - name: test-secret
+    destinations:
+      - type: github-repository
+        repo: owner/repo
+        name: TEST_SECRET
+`,
+			expected: KeyConfig{
+				TokenHelper: []string{"pass", "show"},
+				Secrets: []Secret{
+					{
+						Name: "test-secret",
+						Destinations: []DestinationWrapper{
+							{Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "GitHub App without a private key",
+			yamlContent: `
+github_app:
+  app_id: 12345
+secrets: []
 `,
 			expectError: true,
 		},
@@ -158,6 +647,45 @@ secrets:
 	}
 }
 
+func TestKeyConfig_Hosts(t *testing.T) {
+	config := KeyConfig{
+		Secrets: []Secret{
+			{Destinations: []DestinationWrapper{{Host: ""}, {Host: "github.example.com"}}},
+			{Destinations: []DestinationWrapper{{Host: "github.example.com"}, {Host: "https://ghes.internal/"}}},
+		},
+	}
+	want := []string{"", "github.example.com", "https://ghes.internal/"}
+	if got := config.Hosts(); !cmp.Equal(got, want) {
+		t.Errorf("Expected hosts %v, got %v", want, got)
+	}
+}
+
+func TestKeyConfig_SecretMaxAge(t *testing.T) {
+	config := KeyConfig{MaxAge: Duration(90 * 24 * time.Hour)}
+	if got := config.SecretMaxAge(Secret{}); got != 90*24*time.Hour {
+		t.Errorf("Expected the default max age, got %s", got)
+	}
+	if got := config.SecretMaxAge(Secret{MaxAge: Duration(time.Hour)}); got != time.Hour {
+		t.Errorf("Expected the max age of the secret, got %s", got)
+	}
+	if got := (KeyConfig{}).SecretMaxAge(Secret{}); got != 0 {
+		t.Errorf("Expected no max age, got %s", got)
+	}
+}
+
+func TestDestinationWrapper_GetDescription(t *testing.T) {
+	d := DestinationWrapper{Destination: github.RepositorySecret{Repo: "owner/repo", Name: "TEST_SECRET"}}
+	want := "TEST_SECRET GitHub Repository Secret in the owner/repo repository"
+	if got := d.GetDescription(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	d.Host = "github.example.com"
+	if got := d.GetDescription(); got != want+" on github.example.com" {
+		t.Errorf("Expected the host in the description, got %q", got)
+	}
+}
+
 func TestParseFile_FileNotFound(t *testing.T) {
 	_, err := ParseFile("nonexistent.yaml")
 	if err == nil {
@@ -183,6 +711,114 @@ name: TEST_SECRET`,
 				Name: "TEST_SECRET",
 			},
 		},
+		{
+			name: "Valid GitHub repository codespaces secret",
+			yamlContent: `type: github-repository-codespaces
+repo: owner/repo
+name: TEST_SECRET`,
+			expectError: false,
+			expected: github.CodespacesRepositorySecret{
+				Repo: "owner/repo",
+				Name: "TEST_SECRET",
+			},
+		},
+		{
+			name: "Valid GitHub organization codespaces secret",
+			yamlContent: `type: github-organization-codespaces
+org: owner
+name: TEST_SECRET
+visibility: private`,
+			expectError: false,
+			expected: github.CodespacesOrganizationSecret{
+				Org:        "owner",
+				Name:       "TEST_SECRET",
+				Visibility: "private",
+			},
+		},
+		{
+			name: "Valid GitHub user codespaces secret",
+			yamlContent: `type: github-user-codespaces
+name: TEST_SECRET
+selected_repositories:
+  - owner/repo`,
+			expectError: false,
+			expected: github.CodespacesUserSecret{
+				Name:                 "TEST_SECRET",
+				SelectedRepositories: []string{"owner/repo"},
+			},
+		},
+		{
+			name: "Valid GitHub repository variable",
+			yamlContent: `type: github-repository-variable
+repo: owner/repo
+name: TEST_VARIABLE`,
+			expectError: false,
+			expected: github.RepositoryVariable{
+				Repo: "owner/repo",
+				Name: "TEST_VARIABLE",
+			},
+		},
+		{
+			name: "Valid GitHub repository environment variable",
+			yamlContent: `type: github-repository-environment-variable
+repo: owner/repo
+environment: prod
+name: TEST_VARIABLE`,
+			expectError: false,
+			expected: github.RepositoryEnvironmentVariable{
+				Repo:        "owner/repo",
+				Environment: "prod",
+				Name:        "TEST_VARIABLE",
+			},
+		},
+		{
+			name: "Valid GitHub organization variable",
+			yamlContent: `type: github-organization-variable
+org: owner
+name: TEST_VARIABLE
+visibility: all`,
+			expectError: false,
+			expected: github.OrganizationVariable{
+				Org:        "owner",
+				Name:       "TEST_VARIABLE",
+				Visibility: "all",
+			},
+		},
+		{
+			name: "Valid GitHub repository webhook",
+			yamlContent: `type: github-repository-webhook
+repo: owner/repo
+hook_id: 1234`,
+			expectError: false,
+			expected: github.RepositoryWebhook{
+				Repo:   "owner/repo",
+				HookID: 1234,
+			},
+		},
+		{
+			name: "Valid GitHub organization webhook",
+			yamlContent: `type: github-organization-webhook
+org: owner
+url: https://example.com/webhook`,
+			expectError: false,
+			expected: github.OrganizationWebhook{
+				Org: "owner",
+				URL: "https://example.com/webhook",
+			},
+		},
+		{
+			name: "Valid GitHub deploy key",
+			yamlContent: `type: github-deploy-key
+repo: owner/repo
+title: ci
+read_write: true`,
+			expectError: false,
+			expected: github.DeployKey{
+				Repo:      "owner/repo",
+				Title:     "ci",
+				ReadWrite: true,
+			},
+		},
 		{
 			name:        "Unsupported type",
 			yamlContent: "type: unsupported-type\ndescription: Unsupported type secret",
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// day is the length of the d unit accepted by Duration.
+const day = 24 * time.Hour
+
+// Duration is a length of time written like a Go duration, such as 36h,
+// with an optional leading number of days, such as 90d or 1d12h.
+type Duration time.Duration
+
+// ParseDuration parses a duration with an optional number of days, e.g. 90d, 1d12h or 30m.
+// Negative durations are not allowed.
+func ParseDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q, expected e.g. 90d, 36h or 1d12h", s)
+
+	value := strings.TrimSpace(s)
+	var days time.Duration
+	if i := strings.IndexByte(value, 'd'); i >= 0 {
+		n, err := strconv.Atoi(value[:i])
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		days, value = time.Duration(n)*day, value[i+1:]
+		if value == "" {
+			return days, nil
+		}
+	}
+
+	rest, err := time.ParseDuration(value)
+	if err != nil || rest < 0 {
+		return 0, invalid
+	}
+	return days + rest, nil
+}
+
+// UnmarshalYAML parses the duration from a string such as 90d.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// String formats the duration in days when it is a whole number of them, e.g. 90d.
+func (d Duration) String() string {
+	if d > 0 && time.Duration(d)%day == 0 {
+		return fmt.Sprintf("%dd", time.Duration(d)/day)
+	}
+	return time.Duration(d).String()
+}
+
+// Set parses the duration of a command-line flag.
+func (d *Duration) Set(s string) error {
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Type describes the value of a command-line flag.
+func (d *Duration) Type() string {
+	return "duration"
+}
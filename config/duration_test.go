@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value       string
+		want        time.Duration
+		expectError bool
+	}{
+		{value: "90d", want: 90 * 24 * time.Hour},
+		{value: "1d12h", want: 36 * time.Hour},
+		{value: "36h", want: 36 * time.Hour},
+		{value: "30m", want: 30 * time.Minute},
+		{value: "0d", want: 0},
+		{value: "", expectError: true},
+		{value: "d", expectError: true},
+		{value: "-1d", expectError: true},
+		{value: "1d-1h", expectError: true},
+		{value: "90 days", expectError: true},
+		{value: "1.5d", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseDuration(%q) error = %v, expectError %v", tt.value, err, tt.expectError)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var config struct {
+		MaxAge Duration `yaml:"max_age"`
+	}
+	if err := yaml.Unmarshal([]byte("max_age: 90d"), &config); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := config.MaxAge.String(); got != "90d" {
+		t.Errorf("Expected 90d, got %s", got)
+	}
+
+	var d Duration
+	if err := d.Set("36h"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := d.String(); got != "36h0m0s" {
+		t.Errorf("Expected 36h0m0s, got %s", got)
+	}
+}
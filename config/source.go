@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceStdin is the scalar form of a source reading from standard input.
+const sourceStdin = "stdin"
+
+// Source describes where the value of a secret is read from instead of prompting for it.
+// Exactly one of its fields must be set.
+type Source struct {
+	Env   string `yaml:"env"`
+	File  string `yaml:"file"`
+	Stdin bool   `yaml:"stdin"`
+	// Command is run without a shell, and its standard output is used as the value.
+	Command []string `yaml:"command"`
+}
+
+// UnmarshalYAML custom unmarshaler for Source, which also accepts the scalar form `source: stdin`.
+func (s *Source) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value != sourceStdin {
+			return fmt.Errorf("unsupported source: %s", value.Value)
+		}
+		*s = Source{Stdin: true}
+		return nil
+	}
+
+	type rawSource Source
+	var raw rawSource
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*s = Source(raw)
+	return nil
+}
+
+// validate checks that exactly one source is configured.
+func (s Source) validate() error {
+	count := 0
+	for _, set := range []bool{s.Env != "", s.File != "", s.Stdin, len(s.Command) > 0} {
+		if set {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one of env, file, stdin or command must be set in source, got %d", count)
+	}
+	return nil
+}
+
+// Read returns the value of the secret from the source.
+// The value is returned exactly as read, without trimming whitespace or newlines.
+func (s Source) Read(stdin io.Reader) (string, error) {
+	var value string
+	switch {
+	case s.Env != "":
+		value = os.Getenv(s.Env)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+	case s.File != "":
+		b, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", s.File, err)
+		}
+		value = string(b)
+		if value == "" {
+			return "", fmt.Errorf("file %s is empty", s.File)
+		}
+	case s.Stdin:
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %v", err)
+		}
+		value = string(b)
+		if value == "" {
+			return "", fmt.Errorf("no value provided on stdin")
+		}
+	case len(s.Command) > 0:
+		cmd := exec.Command(s.Command[0], s.Command[1:]...)
+		cmd.Stderr = os.Stderr
+		b, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to run %s: %v", s.Command[0], err)
+		}
+		value = string(b)
+		if value == "" {
+			return "", fmt.Errorf("command %s produced no output", s.Command[0])
+		}
+	default:
+		return "", fmt.Errorf("no source configured")
+	}
+	return value, nil
+}
@@ -0,0 +1,218 @@
+package generate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Generator types.
+const (
+	TypePassword = "password"
+	TypeHex      = "hex"
+	TypeBase64   = "base64"
+	TypeUUID     = "uuid"
+	TypeEd25519  = "ed25519"
+	TypeRSA      = "rsa"
+	TypeHMAC     = "hmac"
+)
+
+// Password character sets.
+const (
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetSymbols      = "symbols"
+	CharsetNumeric      = "numeric"
+	CharsetHex          = "hex"
+)
+
+// Private key formats.
+const (
+	FormatPKCS8   = "pkcs8"
+	FormatOpenSSH = "openssh"
+)
+
+const (
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits    = "0123456789"
+	symbols   = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+)
+
+var charsets = map[string]string{
+	CharsetAlphanumeric: lowercase + uppercase + digits,
+	CharsetSymbols:      lowercase + uppercase + digits + symbols,
+	CharsetNumeric:      digits,
+	CharsetHex:          "0123456789abcdef",
+}
+
+// Default lengths and key sizes.
+const (
+	defaultPasswordLength = 32
+	defaultBytesLength    = 32
+	defaultHMACLength     = 64
+	defaultRSABits        = 4096
+)
+
+// Options configures how a secret value is generated.
+type Options struct {
+	// Type is the kind of value to generate.
+	Type string `yaml:"type"`
+	// Length is the number of characters of a password,
+	// or the number of random bytes for the hex, base64 and hmac types.
+	Length int `yaml:"length"`
+	// Charset is the named character set used for passwords.
+	Charset string `yaml:"charset"`
+	// Characters is a custom character set used for passwords, overriding Charset.
+	Characters string `yaml:"characters"`
+	// Bits is the size of RSA keys.
+	Bits int `yaml:"bits"`
+	// Format is the encoding of generated private keys.
+	Format string `yaml:"format"`
+}
+
+// Validate checks that the options describe a value that can be generated.
+func (o Options) Validate() error {
+	if o.Length < 0 {
+		return fmt.Errorf("length must not be negative, got %d", o.Length)
+	}
+
+	switch o.Type {
+	case TypePassword:
+		if o.Characters == "" {
+			if _, ok := charsets[o.charset()]; !ok {
+				return fmt.Errorf("unsupported charset: %s", o.Charset)
+			}
+		}
+	case TypeHex, TypeBase64, TypeUUID, TypeHMAC:
+	case TypeEd25519, TypeRSA:
+		if o.Format != "" && o.Format != FormatPKCS8 && o.Format != FormatOpenSSH {
+			return fmt.Errorf("unsupported key format: %s", o.Format)
+		}
+		if o.Type == TypeRSA && o.Bits != 0 && o.Bits < 2048 {
+			return fmt.Errorf("rsa keys must be at least 2048 bits, got %d", o.Bits)
+		}
+	case "":
+		return fmt.Errorf("generator type is required")
+	default:
+		return fmt.Errorf("unsupported generator type: %s", o.Type)
+	}
+	return nil
+}
+
+// GenerateSecret generates a new random value according to the options.
+func (o Options) GenerateSecret() (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+
+	switch o.Type {
+	case TypePassword:
+		characters := o.Characters
+		if characters == "" {
+			characters = charsets[o.charset()]
+		}
+		return password(o.length(defaultPasswordLength), characters)
+	case TypeHex:
+		b, err := randomBytes(o.length(defaultBytesLength))
+		return hex.EncodeToString(b), err
+	case TypeBase64:
+		b, err := randomBytes(o.length(defaultBytesLength))
+		return base64.StdEncoding.EncodeToString(b), err
+	case TypeUUID:
+		return uuid()
+	case TypeEd25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate key: %w", err)
+		}
+		return o.encodePrivateKey(privateKey)
+	case TypeRSA:
+		bits := o.Bits
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate key: %w", err)
+		}
+		return o.encodePrivateKey(privateKey)
+	case TypeHMAC:
+		b, err := randomBytes(o.length(defaultHMACLength))
+		return hex.EncodeToString(b), err
+	}
+	return "", fmt.Errorf("unsupported generator type: %s", o.Type)
+}
+
+func (o Options) charset() string {
+	if o.Charset == "" {
+		return CharsetAlphanumeric
+	}
+	return o.Charset
+}
+
+func (o Options) length(defaultLength int) int {
+	if o.Length == 0 {
+		return defaultLength
+	}
+	return o.Length
+}
+
+// encodePrivateKey encodes a private key as PEM in the configured format.
+func (o Options) encodePrivateKey(privateKey any) (string, error) {
+	var block *pem.Block
+	var err error
+	if o.Format == FormatOpenSSH {
+		block, err = ssh.MarshalPrivateKey(privateKey, "")
+	} else {
+		var der []byte
+		der, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// password returns a random string of the given length drawn uniformly from the characters.
+func password(length int, characters string) (string, error) {
+	chars := []rune(characters)
+	size := big.NewInt(int64(len(chars)))
+	result := make([]rune, length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		result[i] = chars[n.Int64()]
+	}
+	return string(result), nil
+}
+
+// randomBytes returns n cryptographically secure random bytes.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// uuid returns a random version 4 UUID.
+func uuid() (string, error) {
+	b, err := randomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,174 @@
+package generate
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestOptions_GenerateSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		check   func(t *testing.T, value string)
+	}{
+		{
+			name:    "default password",
+			options: Options{Type: TypePassword},
+			check: func(t *testing.T, value string) {
+				if !regexp.MustCompile(`^[a-zA-Z0-9]{32}$`).MatchString(value) {
+					t.Errorf("Expected a 32 character alphanumeric password, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "numeric password",
+			options: Options{Type: TypePassword, Length: 8, Charset: CharsetNumeric},
+			check: func(t *testing.T, value string) {
+				if !regexp.MustCompile(`^[0-9]{8}$`).MatchString(value) {
+					t.Errorf("Expected an 8 digit password, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "custom characters",
+			options: Options{Type: TypePassword, Length: 16, Characters: "ab"},
+			check: func(t *testing.T, value string) {
+				if strings.Trim(value, "ab") != "" || len(value) != 16 {
+					t.Errorf("Expected a 16 character password of a and b, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "hex",
+			options: Options{Type: TypeHex, Length: 16},
+			check: func(t *testing.T, value string) {
+				b, err := hex.DecodeString(value)
+				if err != nil || len(b) != 16 {
+					t.Errorf("Expected 16 hex encoded bytes, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "base64",
+			options: Options{Type: TypeBase64},
+			check: func(t *testing.T, value string) {
+				b, err := base64.StdEncoding.DecodeString(value)
+				if err != nil || len(b) != 32 {
+					t.Errorf("Expected 32 base64 encoded bytes, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "uuid",
+			options: Options{Type: TypeUUID},
+			check: func(t *testing.T, value string) {
+				if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(value) {
+					t.Errorf("Expected a version 4 UUID, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "hmac",
+			options: Options{Type: TypeHMAC},
+			check: func(t *testing.T, value string) {
+				b, err := hex.DecodeString(value)
+				if err != nil || len(b) != 64 {
+					t.Errorf("Expected 64 hex encoded bytes, got %q", value)
+				}
+			},
+		},
+		{
+			name:    "ed25519 pkcs8",
+			options: Options{Type: TypeEd25519},
+			check: func(t *testing.T, value string) {
+				key := parsePKCS8(t, value)
+				if _, ok := key.(ed25519.PrivateKey); !ok {
+					t.Errorf("Expected an ed25519 private key, got %T", key)
+				}
+			},
+		},
+		{
+			name:    "ed25519 openssh",
+			options: Options{Type: TypeEd25519, Format: FormatOpenSSH},
+			check: func(t *testing.T, value string) {
+				signer, err := ssh.ParsePrivateKey([]byte(value))
+				if err != nil {
+					t.Fatalf("Expected an OpenSSH private key, got %v", err)
+				}
+				if signer.PublicKey().Type() != ssh.KeyAlgoED25519 {
+					t.Errorf("Expected an ed25519 key, got %s", signer.PublicKey().Type())
+				}
+			},
+		},
+		{
+			name:    "rsa",
+			options: Options{Type: TypeRSA, Bits: 2048},
+			check: func(t *testing.T, value string) {
+				key, ok := parsePKCS8(t, value).(*rsa.PrivateKey)
+				if !ok {
+					t.Fatal("Expected an RSA private key")
+				}
+				if key.N.BitLen() != 2048 {
+					t.Errorf("Expected a 2048 bit key, got %d", key.N.BitLen())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.options.GenerateSecret()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			tt.check(t, value)
+		})
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     Options
+		expectError bool
+	}{
+		{name: "password", options: Options{Type: TypePassword}},
+		{name: "missing type", options: Options{}, expectError: true},
+		{name: "unknown type", options: Options{Type: "unknown"}, expectError: true},
+		{name: "unknown charset", options: Options{Type: TypePassword, Charset: "unknown"}, expectError: true},
+		{name: "negative length", options: Options{Type: TypeHex, Length: -1}, expectError: true},
+		{name: "small rsa key", options: Options{Type: TypeRSA, Bits: 1024}, expectError: true},
+		{name: "unknown key format", options: Options{Type: TypeEd25519, Format: "der"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func parsePKCS8(t *testing.T, value string) any {
+	t.Helper()
+	block, _ := pem.Decode([]byte(value))
+	if block == nil || block.Type != "PRIVATE KEY" {
+		t.Fatalf("Expected a PEM encoded private key, got %q", value)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("Expected a PKCS #8 private key, got %v", err)
+	}
+	return key
+}
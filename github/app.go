@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+const (
+	// appTokenLifetime is how long a GitHub App JWT is valid for. GitHub allows at most 10 minutes.
+	appTokenLifetime = 9 * time.Minute
+	// appClockSkew backdates the JWT to allow for clock drift between the local machine and GitHub.
+	appClockSkew = time.Minute
+	// installationTokenRefresh is how long before it expires an installation token is replaced.
+	installationTokenRefresh = 5 * time.Minute
+)
+
+// AppCredentials authenticates as a GitHub App installation instead of with a personal access token.
+// When no installation ID is given, the installation is discovered for the owner of each destination.
+type AppCredentials struct {
+	AppID          int64  `yaml:"app_id"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	InstallationID int64  `yaml:"installation_id"`
+}
+
+// Validate checks that the app ID and private key file are set.
+func (a AppCredentials) Validate() error {
+	if a.AppID == 0 {
+		return fmt.Errorf("app_id is required for GitHub App authentication")
+	}
+	if a.PrivateKeyFile == "" {
+		return fmt.Errorf("private_key_file is required for GitHub App authentication")
+	}
+	return nil
+}
+
+// readPrivateKey reads the PEM encoded RSA private key of the app.
+func (a AppCredentials) readPrivateKey() (*rsa.PrivateKey, error) {
+	b, err := os.ReadFile(a.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %v", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key %s is not PEM encoded", a.PrivateKeyFile)
+	}
+
+	// GitHub issues PKCS #1 keys, but converted PKCS #8 keys are accepted too.
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// appJWT returns a JSON Web Token authenticating as the app, signed with RS256.
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appTokenLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App token: %v", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appTransport authenticates requests as the app itself, which is only allowed
+// for finding installations and creating their access tokens.
+type appTransport struct {
+	base  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := appJWT(t.appID, t.key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationTransport authenticates requests as an installation of the app,
+// creating a new installation token shortly before the current one expires.
+type installationTransport struct {
+	base           http.RoundTripper
+	app            *github.Client
+	installationID int64
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (t *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationToken returns a valid installation token, creating one if needed.
+func (t *installationTransport) installationToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Until(t.expiresAt) > installationTokenRefresh {
+		return t.token, nil
+	}
+	token, _, err := t.app.Apps.CreateInstallationToken(ctx, t.installationID, nil)
+	if err != nil {
+		return "", apiError(fmt.Sprintf("installation %d", t.installationID), fmt.Errorf("failed to create GitHub App installation token: %w", err))
+	}
+	t.token, t.expiresAt = token.GetToken(), token.GetExpiresAt().Time
+	return t.token, nil
+}
+
+// findInstallation returns the ID of the app installation for the organization or user.
+func findInstallation(ctx context.Context, app *github.Client, owner string) (int64, error) {
+	installation, _, err := app.Apps.FindOrganizationInstallation(ctx, owner)
+	if statusCode(err) == http.StatusNotFound {
+		installation, _, err = app.Apps.FindUserInstallation(ctx, owner)
+	}
+	if statusCode(err) == http.StatusNotFound {
+		return 0, fmt.Errorf("the GitHub App is not installed for %s", owner)
+	}
+	if err != nil {
+		return 0, apiError(owner, fmt.Errorf("failed to find the GitHub App installation for %s: %w", owner, err))
+	}
+	return installation.GetID(), nil
+}
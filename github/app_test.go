@@ -0,0 +1,264 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	token, err := appJWT(12345, key, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	claims := verifyJWT(t, token, &key.PublicKey)
+
+	want := map[string]any{
+		"iat": float64(now.Add(-appClockSkew).Unix()),
+		"exp": float64(now.Add(appTokenLifetime).Unix()),
+		"iss": "12345",
+	}
+	for name, value := range want {
+		if claims[name] != value {
+			t.Errorf("Expected claim %s to be %v, got %v", name, value, claims[name])
+		}
+	}
+}
+
+func TestAppCredentials_ReadPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		content     []byte
+		expectError bool
+	}{
+		{
+			name:    "PKCS #1",
+			content: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+		{
+			name:    "PKCS #8",
+			content: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		},
+		{
+			name:        "Not PEM encoded",
+			content:     []byte("not a key"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "app.pem")
+			if err := os.WriteFile(file, tt.content, 0o600); err != nil {
+				t.Fatalf("Failed to write temp file: %v", err)
+			}
+			got, err := AppCredentials{AppID: 1, PrivateKeyFile: file}.readPrivateKey()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("readPrivateKey error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && !got.Equal(key) {
+				t.Errorf("Expected the private key to round trip")
+			}
+		})
+	}
+}
+
+func TestClients_GitHubApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	var lookups, tokens atomic.Int32
+	mux := http.NewServeMux()
+	appHandler := func(id int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			lookups.Add(1)
+			verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+			fmt.Fprintf(w, `{"id":%d}`, id)
+		}
+	}
+	mux.HandleFunc("GET /api/v3/orgs/octo/installation", appHandler(42))
+	mux.HandleFunc("GET /api/v3/orgs/someone/installation", http.NotFound)
+	mux.HandleFunc("GET /api/v3/users/someone/installation", appHandler(43))
+	mux.HandleFunc("GET /api/v3/orgs/nobody/installation", http.NotFound)
+	mux.HandleFunc("GET /api/v3/users/nobody/installation", http.NotFound)
+	mux.HandleFunc("POST /api/v3/app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		tokens.Add(1)
+		verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+		fmt.Fprintf(w, `{"token":"installation-%s","expires_at":"%s"}`, r.PathValue("id"), time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("GET /api/v3/repos/{owner}/repo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"full_name":%q}`, r.Header.Get("Authorization"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	clients, err := NewClients([]string{server.URL}, Auth{App: &AppCredentials{AppID: 12345, PrivateKeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		owner string
+		want  string
+	}{
+		{owner: "octo", want: "Bearer installation-42"},
+		{owner: "octo", want: "Bearer installation-42"},
+		{owner: "someone", want: "Bearer installation-43"},
+	} {
+		client, err := clients.Get(ctx, server.URL, tt.owner)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		repo, _, err := client.Repositories.Get(ctx, tt.owner, "repo")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := repo.GetFullName(); got != tt.want {
+			t.Errorf("Expected %s to be authenticated with %q, got %q", tt.owner, tt.want, got)
+		}
+	}
+	if got := lookups.Load(); got != 2 {
+		t.Errorf("Expected 2 installation lookups, got %d", got)
+	}
+	if got := tokens.Load(); got != 2 {
+		t.Errorf("Expected 2 installation tokens, got %d", got)
+	}
+
+	if _, err := clients.Get(ctx, server.URL, "nobody"); err == nil {
+		t.Errorf("Expected an error for an owner without an installation")
+	}
+	if _, err := clients.Get(ctx, server.URL, ""); err == nil {
+		t.Errorf("Expected an error for a destination without an owner")
+	}
+}
+
+// verifyJWT checks the RS256 signature of the token and returns its claims.
+func TestClients_GitHubApp_ConcurrentLookups(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	// The lookup for slow blocks until the lookup for fast has completed.
+	release := make(chan struct{})
+	var lookups atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/orgs/slow/installation", func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		<-release
+		fmt.Fprint(w, `{"id":42}`)
+	})
+	mux.HandleFunc("GET /api/v3/orgs/fast/installation", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":43}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	clients, err := NewClients([]string{server.URL}, Auth{App: &AppCredentials{AppID: 12345, PrivateKeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := clients.Get(ctx, server.URL, "slow"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := clients.Get(ctx, server.URL, "fast")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the lookup for fast not to wait for the lookup for slow")
+	}
+	close(release)
+	wg.Wait()
+
+	if got := lookups.Load(); got != 1 {
+		t.Errorf("Expected 1 installation lookup for slow, got %d", got)
+	}
+}
+
+func verifyJWT(t *testing.T, token string, key *rsa.PublicKey) map[string]any {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT with 3 parts, got %q", token)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("Expected a valid signature, got %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return claims
+}
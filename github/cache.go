@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// cache holds the public keys and repository IDs fetched during a run, so that
+// rotating several secrets into the same scope only fetches them once.
+// Failed fetches are not cached.
+type cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry holds a single cached value. Its mutex is held while the value is
+// fetched, so that concurrent updates of the same scope wait for one fetch.
+type cacheEntry struct {
+	mu     sync.Mutex
+	value  any
+	cached bool
+}
+
+func newCache() *cache {
+	return &cache{entries: map[string]*cacheEntry{}}
+}
+
+// load returns the cached value for the key, calling fetch if it is not cached yet.
+// A nil cache always calls fetch.
+func load[T any](c *cache, key string, fetch func() (T, error)) (T, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.cached {
+		return entry.value.(T), nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	entry.value, entry.cached = value, true
+	return value, nil
+}
+
+// cacheKey builds a cache key from the kind of value and its scope, e.g. the service and the API path of a repository.
+func cacheKey(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// publicKey returns the public key used to encrypt secrets in the scope, fetching it once per run.
+func (ghc Client) publicKey(scope string, fetch func() (*github.PublicKey, error)) (*github.PublicKey, error) {
+	key, err := load(ghc.cache, cacheKey("public-key", scope), fetch)
+	if err != nil {
+		return nil, &KeyFetchError{Scope: scope, Err: err}
+	}
+	return key, nil
+}
+
+// repositoryID returns the ID of the repository, fetching it once per run.
+func (ghc Client) repositoryID(ctx context.Context, owner string, repo string) (int64, error) {
+	return load(ghc.cache, cacheKey("repository-id", owner, repo), func() (int64, error) {
+		repository, _, err := ghc.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return 0, repositoryAPIError(owner+"/"+repo, fmt.Errorf("failed to get repository: %w", err))
+		}
+		return repository.GetID(), nil
+	})
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestClient_CachesPublicKeysAndRepositoryIDs(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, _, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	publicKey := fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:]))
+
+	var repoGets, repoKeyGets, envKeyGets atomic.Int32
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		repoGets.Add(1)
+		fmt.Fprint(w, `{"id":1234}`)
+	})
+	mux.HandleFunc("/repos/o/r/actions/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		repoKeyGets.Add(1)
+		fmt.Fprint(w, publicKey)
+	})
+	mux.HandleFunc("/repos/o/r/actions/secrets/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/repositories/1234/environments/env/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		envKeyGets.Add(1)
+		fmt.Fprint(w, publicKey)
+	})
+	mux.HandleFunc("/repositories/1234/environments/env/secrets/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s := RepositorySecret{Repo: "o/r", Name: fmt.Sprintf("SECRET_%d", i)}
+			if err := s.UpdateSecret(ctx, client, "value"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			s := RepositoryEnvironmentSecret{Repo: "o/r", Name: fmt.Sprintf("SECRET_%d", i), Environment: "env"}
+			if err := s.UpdateSecret(ctx, client, "value"); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for name, got := range map[string]int32{
+		"repository":             repoGets.Load(),
+		"repository public key":  repoKeyGets.Load(),
+		"environment public key": envKeyGets.Load(),
+	} {
+		if got != 1 {
+			t.Errorf("Expected the %s to be fetched once, got %d", name, got)
+		}
+	}
+}
+
+func TestLoad_DoesNotCacheErrors(t *testing.T) {
+	c := newCache()
+	errFetch := errors.New("fetch failed")
+
+	calls := 0
+	fetch := func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errFetch
+		}
+		return "value", nil
+	}
+
+	if _, err := load(c, "key", fetch); !errors.Is(err, errFetch) {
+		t.Fatalf("Expected %v, got %v", errFetch, err)
+	}
+	for range 2 {
+		value, err := load(c, "key", fetch)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if value != "value" {
+			t.Errorf("Expected value, got %s", value)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 fetches, got %d", calls)
+	}
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// TokenScopes returns the OAuth scopes of the token used for the host.
+// Fine-grained tokens and GitHub App installations have permissions instead of scopes,
+// which is reported with ok set to false; their access is only verified by checking each destination.
+func (c *Clients) TokenScopes(ctx context.Context, host string) (scopes []string, ok bool, err error) {
+	if c.app != nil {
+		return nil, false, nil
+	}
+	client, err := c.Get(ctx, host, "")
+	if err != nil {
+		return nil, false, err
+	}
+	return client.tokenScopes(ctx)
+}
+
+// tokenScopes returns the scopes GitHub reports in the X-OAuth-Scopes header for the token.
+// The API root is used since it exists on every host, unlike the rate limit endpoint,
+// which GitHub Enterprise Server only serves when rate limiting is enabled.
+func (ghc Client) tokenScopes(ctx context.Context) ([]string, bool, error) {
+	req, err := ghc.NewRequest(http.MethodGet, "", nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check the token: %v", err)
+	}
+	resp, err := ghc.Do(ctx, req, nil)
+	if err != nil {
+		return nil, false, apiError("the token", fmt.Errorf("failed to check the token: %w", err))
+	}
+	if _, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; !ok {
+		return nil, false, nil
+	}
+
+	scopes := []string{}
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, true, nil
+}
+
+// checkEnvironment verifies that the environment exists in the repository.
+// Updating a secret or variable in an environment that does not exist fails rather than creating it.
+func (ghc Client) checkEnvironment(ctx context.Context, owner string, repo string, environment string) error {
+	_, resp, err := ghc.Repositories.GetEnvironment(ctx, owner, repo, environment)
+	if isNotFound(resp) {
+		return fmt.Errorf("environment %s not found in the %s/%s repository", environment, owner, repo)
+	}
+	if err != nil {
+		return apiError(owner+"/"+repo, fmt.Errorf("failed to get environment: %w", err))
+	}
+	return nil
+}
+
+// checkHook verifies that the webhook exists, using getHook to fetch it.
+func checkHook(hookID int64, getHook func() (*github.Hook, *github.Response, error)) error {
+	_, resp, err := getHook()
+	if isNotFound(resp) {
+		return fmt.Errorf("webhook %d not found", hookID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return nil
+}
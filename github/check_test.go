@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testPublicKey = `{"key_id":"1234","key":"2Sg8iYjAxxmI2LvUXpJjkYrMxURPc8r+dB7TJyvv1234"}`
+
+func TestClient_TokenScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     []string
+		wantScopes []string
+		wantOK     bool
+	}{
+		{
+			name:       "classic token",
+			header:     []string{"repo, admin:org,codespace"},
+			wantScopes: []string{"repo", "admin:org", "codespace"},
+			wantOK:     true,
+		},
+		{
+			name:       "classic token without scopes",
+			header:     []string{""},
+			wantScopes: []string{},
+			wantOK:     true,
+		},
+		{
+			name: "fine-grained token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _ := setup(t)
+			mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != nil {
+					w.Header()["X-Oauth-Scopes"] = tt.header
+				}
+				fmt.Fprint(w, `{}`)
+			})
+			// GitHub Enterprise Server does not serve the rate limit endpoint when rate limiting is disabled.
+			mux.HandleFunc("GET /rate_limit", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message":"Rate limiting is not enabled."}`)
+			})
+
+			scopes, ok, err := client.tokenScopes(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if ok != tt.wantOK || !cmp.Equal(scopes, tt.wantScopes) {
+				t.Errorf("Expected %v (%v), got %v (%v)", tt.wantScopes, tt.wantOK, scopes, ok)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination interface {
+			Check(ctx context.Context, client Client) error
+		}
+		handlers    map[string]http.HandlerFunc
+		expectError bool
+		check       func(t *testing.T, err error)
+	}{
+		{
+			name:        "repository secret",
+			destination: RepositorySecret{Repo: "o/r", Name: "mysecret"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/actions/secrets/public-key": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, testPublicKey)
+				},
+			},
+		},
+		{
+			name:        "repository secret in a missing repository",
+			destination: RepositorySecret{Repo: "o/typo", Name: "mysecret"},
+			expectError: true,
+			check: func(t *testing.T, err error) {
+				var target *RepoNotFoundError
+				if !errors.As(err, &target) || target.Repo != "o/typo" {
+					t.Errorf("Expected a RepoNotFoundError for o/typo, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "environment secret",
+			destination: RepositoryEnvironmentSecret{Repo: "o/r", Name: "mysecret", Environment: "production"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+				"GET /repos/o/r/environments/production": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"name":"production"}`)
+				},
+				"GET /repositories/1/environments/production/secrets/public-key": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, testPublicKey)
+				},
+			},
+		},
+		{
+			name:        "environment secret in a missing environment",
+			destination: RepositoryEnvironmentSecret{Repo: "o/r", Name: "mysecret", Environment: "prod"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+			expectError: true,
+			check: func(t *testing.T, err error) {
+				if !strings.Contains(err.Error(), "environment prod not found") {
+					t.Errorf("Expected a missing environment error, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "organization secret with a missing selected repository",
+			destination: OrganizationSecret{Org: "o", Name: "mysecret", SelectedRepositories: []string{"missing"}},
+			expectError: true,
+			check: func(t *testing.T, err error) {
+				var target *RepoNotFoundError
+				if !errors.As(err, &target) || target.Repo != "o/missing" {
+					t.Errorf("Expected a RepoNotFoundError for o/missing, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "organization secret with an invalid visibility",
+			destination: OrganizationSecret{Org: "o", Name: "mysecret", Visibility: "public"},
+			expectError: true,
+		},
+		{
+			name:        "organization variable without permission",
+			destination: OrganizationVariable{Org: "o", Name: "MY_VARIABLE"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o/actions/variables": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectError: true,
+			check: func(t *testing.T, err error) {
+				var target *PermissionDeniedError
+				if !errors.As(err, &target) || target.Resource != "o" {
+					t.Errorf("Expected a PermissionDeniedError for o, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "repository webhook",
+			destination: RepositoryWebhook{Repo: "o/r", HookID: 1},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/hooks/1": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+		},
+		{
+			name:        "missing repository webhook",
+			destination: RepositoryWebhook{Repo: "o/r", HookID: 2},
+			expectError: true,
+			check: func(t *testing.T, err error) {
+				if !strings.Contains(err.Error(), "webhook 2 not found") {
+					t.Errorf("Expected a missing webhook error, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "deploy key",
+			destination: DeployKey{Repo: "o/r", Title: "deploy"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/keys": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `[]`)
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _ := setup(t)
+			for pattern, handler := range tt.handlers {
+				mux.HandleFunc(pattern, handler)
+			}
+
+			err := tt.destination.Check(context.Background(), client)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Check error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.check != nil {
+				tt.check(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// CodespacesRepositorySecret represents a GitHub Codespaces repository secret destination.
+type CodespacesRepositorySecret struct {
+	Repo string `yaml:"repo"`
+	Name string `yaml:"name"`
+}
+
+// GetDescription returns the destination description.
+func (d CodespacesRepositorySecret) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Codespaces Repository Secret in the %s repository", d.Name, d.Repo)
+}
+
+// Owner returns the owner of the repository.
+func (d CodespacesRepositorySecret) Owner() string {
+	return repoOwner(d.Repo)
+}
+
+// UpdateSecret updates the Codespaces secret in the repository.
+func (d CodespacesRepositorySecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.publicKey(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %v", err)
+	}
+
+	ghSecret := secret{
+		Name:           d.Name,
+		KeyID:          key.GetKeyID(),
+		EncryptedValue: encryptedValue,
+	}
+
+	return repositoryAPIError(d.Repo, client.updateCodespacesRepositorySecret(ctx, owner, repo, ghSecret))
+}
+
+// Check verifies that the repository exists and that its public key can be read.
+func (d CodespacesRepositorySecret) Check(ctx context.Context, client Client) error {
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the repository and when it was last updated.
+func (d CodespacesRepositorySecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Codespaces.GetRepoSecret(ctx, owner, repo, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// publicKey returns the public key used to encrypt Codespaces secrets in the repository.
+func (d CodespacesRepositorySecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.publicKey(cacheKey("codespaces", "repos", owner, repo), func() (*github.PublicKey, error) {
+		key, _, err := client.Codespaces.GetRepoPublicKey(ctx, owner, repo)
+		return key, repositoryAPIError(d.Repo, err)
+	})
+}
+
+// CodespacesOrganizationSecret represents a GitHub Codespaces organization secret destination.
+type CodespacesOrganizationSecret struct {
+	Org                  string   `yaml:"org"`
+	Name                 string   `yaml:"name"`
+	Visibility           string   `yaml:"visibility"`
+	SelectedRepositories []string `yaml:"selected_repositories"`
+}
+
+// GetDescription returns the destination description.
+func (d CodespacesOrganizationSecret) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Codespaces Organization Secret in the %s organization", d.Name, d.Org)
+}
+
+// Owner returns the organization.
+func (d CodespacesOrganizationSecret) Owner() string {
+	return d.Org
+}
+
+// Validate checks the visibility against the selected repositories.
+func (d CodespacesOrganizationSecret) Validate() error {
+	return validateOrganizationVisibility(d.Visibility, d.SelectedRepositories)
+}
+
+// UpdateSecret updates the Codespaces secret in the organization.
+func (d CodespacesOrganizationSecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	visibility := organizationVisibility(d.Visibility, d.SelectedRepositories)
+
+	repositoryIDs, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.publicKey(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %v", err)
+	}
+
+	ghSecret := secret{
+		Name:                  d.Name,
+		KeyID:                 key.GetKeyID(),
+		EncryptedValue:        encryptedValue,
+		Visibility:            visibility,
+		SelectedRepositoryIDs: repositoryIDs,
+	}
+
+	return apiError(d.Org, client.updateCodespacesOrganizationSecret(ctx, d.Org, ghSecret))
+}
+
+// Check verifies the visibility and the selected repositories, and that the public key of the organization can be read.
+func (d CodespacesOrganizationSecret) Check(ctx context.Context, client Client) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories); err != nil {
+		return err
+	}
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the organization and when it was last updated.
+func (d CodespacesOrganizationSecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Codespaces.GetOrgSecret(ctx, d.Org, d.Name)
+	})
+	return status, apiError(d.Org, err)
+}
+
+// publicKey returns the public key used to encrypt Codespaces secrets in the organization.
+func (d CodespacesOrganizationSecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	return client.publicKey(cacheKey("codespaces", "orgs", d.Org), func() (*github.PublicKey, error) {
+		key, _, err := client.Codespaces.GetOrgPublicKey(ctx, d.Org)
+		return key, apiError(d.Org, err)
+	})
+}
+
+// CodespacesUserSecret represents a Codespaces secret destination for the authenticated user.
+type CodespacesUserSecret struct {
+	Name                 string   `yaml:"name"`
+	SelectedRepositories []string `yaml:"selected_repositories"`
+}
+
+// GetDescription returns the destination description.
+func (d CodespacesUserSecret) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Codespaces User Secret for the authenticated user", d.Name)
+}
+
+// UpdateSecret updates the Codespaces secret of the authenticated user.
+// Repositories in selected_repositories must use the owner/repo format.
+func (d CodespacesUserSecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	repositoryIDs, err := client.repositoryIDs(ctx, "", d.SelectedRepositories)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.publicKey(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %v", err)
+	}
+
+	ghSecret := secret{
+		Name:                  d.Name,
+		KeyID:                 key.GetKeyID(),
+		EncryptedValue:        encryptedValue,
+		SelectedRepositoryIDs: repositoryIDs,
+	}
+
+	return apiError("the authenticated user", client.updateCodespacesUserSecret(ctx, ghSecret))
+}
+
+// Check verifies the selected repositories, and that the public key of the authenticated user can be read.
+func (d CodespacesUserSecret) Check(ctx context.Context, client Client) error {
+	if _, err := client.repositoryIDs(ctx, "", d.SelectedRepositories); err != nil {
+		return err
+	}
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists for the authenticated user and when it was last updated.
+func (d CodespacesUserSecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Codespaces.GetUserSecret(ctx, d.Name)
+	})
+	return status, apiError("the authenticated user", err)
+}
+
+// publicKey returns the public key used to encrypt the Codespaces secrets of the authenticated user.
+func (d CodespacesUserSecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	return client.publicKey(cacheKey("codespaces", "user"), func() (*github.PublicKey, error) {
+		key, _, err := client.Codespaces.GetUserPublicKey(ctx)
+		return key, apiError("the authenticated user", err)
+	})
+}
+
+// updateCodespacesRepositorySecret updates a GitHub Codespaces secret in the repository.
+func (ghc Client) updateCodespacesRepositorySecret(ctx context.Context, owner string, repo string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
+	s := &github.EncryptedSecret{
+		Name:           secret.Name,
+		KeyID:          secret.KeyID,
+		EncryptedValue: secret.EncryptedValue,
+	}
+	_, err := ghc.Codespaces.CreateOrUpdateRepoSecret(ctx, owner, repo, s)
+	return err
+}
+
+// updateCodespacesOrganizationSecret updates a GitHub Codespaces secret in the organization.
+func (ghc Client) updateCodespacesOrganizationSecret(ctx context.Context, org string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
+	s := &github.EncryptedSecret{
+		Name:                  secret.Name,
+		KeyID:                 secret.KeyID,
+		EncryptedValue:        secret.EncryptedValue,
+		Visibility:            secret.Visibility,
+		SelectedRepositoryIDs: secret.SelectedRepositoryIDs,
+	}
+	_, err := ghc.Codespaces.CreateOrUpdateOrgSecret(ctx, org, s)
+	return err
+}
+
+// updateCodespacesUserSecret updates a GitHub Codespaces secret for the authenticated user.
+func (ghc Client) updateCodespacesUserSecret(ctx context.Context, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
+	s := &github.EncryptedSecret{
+		Name:                  secret.Name,
+		KeyID:                 secret.KeyID,
+		EncryptedValue:        secret.EncryptedValue,
+		SelectedRepositoryIDs: secret.SelectedRepositoryIDs,
+	}
+	_, err := ghc.Codespaces.CreateOrUpdateUserSecret(ctx, s)
+	return err
+}
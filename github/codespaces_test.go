@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestCodespacesRepositorySecret_UpdateSecret_ValidRepo(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r/codespaces/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:])))
+	})
+
+	mux.HandleFunc("/repos/o/r/codespaces/secrets/mysecret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var reqBody github.EncryptedSecret
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		validateSodiumSecret(t, "mysecretvalue", reqBody.EncryptedValue, public, private)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	s := CodespacesRepositorySecret{
+		Repo: "o/r",
+		Name: "mysecret",
+	}
+	err = s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCodespacesRepositorySecret_UpdateSecret_InvalidRepo(t *testing.T) {
+	client, _, _ := setup(t)
+
+	ctx := context.Background()
+	s := CodespacesRepositorySecret{
+		Repo: "invalid/repo/format",
+		Name: "mysecret",
+	}
+	err := s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestCodespacesOrganizationSecret_UpdateSecret_ValidOrg(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/orgs/o/codespaces/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:])))
+	})
+
+	mux.HandleFunc("/orgs/o/codespaces/secrets/mysecret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var reqBody github.EncryptedSecret
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.Visibility != "all" {
+			t.Errorf("Expected visibility all, got %s", reqBody.Visibility)
+		}
+		validateSodiumSecret(t, "mysecretvalue", reqBody.EncryptedValue, public, private)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	s := CodespacesOrganizationSecret{
+		Org:        "o",
+		Name:       "mysecret",
+		Visibility: "all",
+	}
+	err = s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCodespacesUserSecret_UpdateSecret_SelectedRepositories(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":1234}`)
+	})
+
+	mux.HandleFunc("/user/codespaces/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:])))
+	})
+
+	mux.HandleFunc("/user/codespaces/secrets/mysecret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var reqBody github.EncryptedSecret
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if diff := cmp.Diff(github.SelectedRepoIDs{1234}, reqBody.SelectedRepositoryIDs); diff != "" {
+			t.Errorf("Unexpected selected repository IDs (-want +got):\n%s", diff)
+		}
+		validateSodiumSecret(t, "mysecretvalue", reqBody.EncryptedValue, public, private)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	s := CodespacesUserSecret{
+		Name:                 "mysecret",
+		SelectedRepositories: []string{"o/r"},
+	}
+	err = s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCodespacesUserSecret_UpdateSecret_RepositoryWithoutOwner(t *testing.T) {
+	client, _, _ := setup(t)
+
+	ctx := context.Background()
+	s := CodespacesUserSecret{
+		Name:                 "mysecret",
+		SelectedRepositories: []string{"r"},
+	}
+	err := s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Auth configures how the clients authenticate.
+// Unless a GitHub App is configured, the token of each host is looked up from, in order:
+// environment variables, the gh CLI, the token helper and the local credential store.
+type Auth struct {
+	// App authenticates as a GitHub App installation instead of with tokens.
+	App *AppCredentials
+	// TokenHelper is a command that prints the token of the host passed as its last argument.
+	TokenHelper []string
+	// Passphrase returns the passphrase of the credential store, e.g. by prompting for it.
+	// It is only called when a token is not found anywhere else and the store exists.
+	Passphrase func() (string, error)
+}
+
+// tokenLookup is a single source of tokens in the credential chain.
+// It returns an empty token when it has none for the host.
+type tokenLookup func(h host) (token string, source string, err error)
+
+// findToken returns the token of the host from the first source in the chain that has one,
+// along with a description of that source.
+func (a Auth) findToken(h host) (string, string, error) {
+	for _, lookup := range []tokenLookup{envToken, ghToken, a.helperToken, a.storeToken} {
+		token, source, err := lookup(h)
+		if err != nil {
+			return "", "", err
+		}
+		if token != "" {
+			return token, source, nil
+		}
+	}
+	return "", "", &MissingCredentialsError{Host: h.name, Variables: h.tokenVariables()}
+}
+
+// envToken returns the token of the host from its environment variables.
+func envToken(h host) (string, string, error) {
+	for _, variable := range h.tokenVariables() {
+		if token := os.Getenv(variable); token != "" {
+			return token, "the " + variable + " environment variable", nil
+		}
+	}
+	return "", "", nil
+}
+
+// ghHost is the configuration of a host in the gh CLI's hosts.yml.
+type ghHost struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+// ghToken returns the token of the host the gh CLI is logged in to.
+// Older gh versions store it in hosts.yml, newer ones in the OS keyring, which is read with `gh auth token`.
+func ghToken(h host) (string, string, error) {
+	path := filepath.Join(ghConfigDir(), "hosts.yml")
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var hosts map[string]ghHost
+	if err := yaml.Unmarshal(b, &hosts); err != nil {
+		return "", "", fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	entry, ok := hosts[h.name]
+	if !ok {
+		return "", "", nil
+	}
+	if entry.OAuthToken != "" {
+		return entry.OAuthToken, "the gh CLI configuration in " + path, nil
+	}
+
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", "", nil
+	}
+	out, err := exec.Command("gh", "auth", "token", "--hostname", h.name).Output()
+	if err != nil {
+		return "", "", nil
+	}
+	return strings.TrimSpace(string(out)), "the gh CLI credential store", nil
+}
+
+// ghConfigDir returns the configuration directory of the gh CLI.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
+
+// helperToken returns the token printed by the token helper command for the host.
+func (a Auth) helperToken(h host) (string, string, error) {
+	if len(a.TokenHelper) == 0 {
+		return "", "", nil
+	}
+
+	args := append(a.TokenHelper[1:len(a.TokenHelper):len(a.TokenHelper)], h.name)
+	cmd := exec.Command(a.TokenHelper[0], args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("token helper %s failed: %v: %s", a.TokenHelper[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), "the token helper " + a.TokenHelper[0], nil
+}
+
+// storeToken returns the token of the host from the local credential store.
+func (a Auth) storeToken(h host) (string, string, error) {
+	store, err := DefaultCredentialStore()
+	if err != nil || !store.Exists() {
+		return "", "", nil
+	}
+
+	passphrase := os.Getenv("KEY_ROTATOR_CREDENTIALS_PASSPHRASE")
+	if passphrase == "" {
+		if a.Passphrase == nil {
+			return "", "", fmt.Errorf("a passphrase is required to read the credential store %s", store.Path)
+		}
+		if passphrase, err = a.Passphrase(); err != nil {
+			return "", "", err
+		}
+	}
+
+	tokens, err := store.Load(passphrase)
+	if err != nil {
+		return "", "", err
+	}
+	return tokens[h.name], "the credential store in " + store.Path, nil
+}
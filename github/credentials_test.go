@@ -0,0 +1,181 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolateCredentials clears every source of tokens, so that tests do not pick up the user's credentials.
+func isolateCredentials(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, variable := range []string{"GITHUB_TOKEN", "GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "KEY_ROTATOR_CREDENTIALS_PASSPHRASE"} {
+		t.Setenv(variable, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", filepath.Join(dir, "gh"))
+	t.Setenv("KEY_ROTATOR_CREDENTIALS_FILE", filepath.Join(dir, "credentials.json"))
+	// gh would otherwise be asked for a token from the OS keyring.
+	t.Setenv("PATH", "")
+}
+
+func writeGhHosts(t *testing.T, content string) {
+	t.Helper()
+	dir := os.Getenv("GH_CONFIG_DIR")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuth_FindToken(t *testing.T) {
+	echo := []string{"/bin/echo", "helper-token-for"}
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T)
+		auth        Auth
+		host        string
+		wantToken   string
+		wantSource  string
+		expectError bool
+	}{
+		{
+			name:        "No credentials",
+			expectError: true,
+		},
+		{
+			name: "GITHUB_TOKEN before GH_TOKEN",
+			setup: func(t *testing.T) {
+				t.Setenv("GITHUB_TOKEN", "github-token")
+				t.Setenv("GH_TOKEN", "gh-token")
+			},
+			wantToken:  "github-token",
+			wantSource: "GITHUB_TOKEN",
+		},
+		{
+			name: "GH_TOKEN",
+			setup: func(t *testing.T) {
+				t.Setenv("GH_TOKEN", "gh-token")
+			},
+			wantToken:  "gh-token",
+			wantSource: "GH_TOKEN",
+		},
+		{
+			name: "gh CLI hosts.yml before the token helper",
+			setup: func(t *testing.T) {
+				writeGhHosts(t, "github.com:\n  oauth_token: hosts-token\n  user: octocat\n")
+			},
+			auth:       Auth{TokenHelper: echo},
+			wantToken:  "hosts-token",
+			wantSource: "hosts.yml",
+		},
+		{
+			name: "gh CLI hosts.yml for another host",
+			setup: func(t *testing.T) {
+				writeGhHosts(t, "github.example.com:\n  oauth_token: hosts-token\n")
+			},
+			auth:       Auth{TokenHelper: echo},
+			wantToken:  "helper-token-for github.com",
+			wantSource: "token helper",
+		},
+		{
+			name:       "Token helper with the hostname",
+			auth:       Auth{TokenHelper: echo},
+			host:       "https://GitHub.Example.com/api/v3/",
+			wantToken:  "helper-token-for github.example.com",
+			wantSource: "token helper",
+		},
+		{
+			name:        "Failing token helper",
+			auth:        Auth{TokenHelper: []string{"/bin/false"}},
+			expectError: true,
+		},
+		{
+			name: "Credential store with the passphrase from the environment",
+			setup: func(t *testing.T) {
+				saveStore(t, "passphrase", map[string]string{"github.com": "store-token"})
+				t.Setenv("KEY_ROTATOR_CREDENTIALS_PASSPHRASE", "passphrase")
+			},
+			wantToken:  "store-token",
+			wantSource: "credential store",
+		},
+		{
+			name: "Credential store with a prompted passphrase",
+			setup: func(t *testing.T) {
+				saveStore(t, "passphrase", map[string]string{"github.com": "store-token"})
+			},
+			auth:       Auth{Passphrase: func() (string, error) { return "passphrase", nil }},
+			wantToken:  "store-token",
+			wantSource: "credential store",
+		},
+		{
+			name: "Credential store without a passphrase",
+			setup: func(t *testing.T) {
+				saveStore(t, "passphrase", map[string]string{"github.com": "store-token"})
+			},
+			expectError: true,
+		},
+		{
+			name: "Credential store without the host",
+			setup: func(t *testing.T) {
+				saveStore(t, "passphrase", map[string]string{"github.example.com": "store-token"})
+				t.Setenv("KEY_ROTATOR_CREDENTIALS_PASSPHRASE", "passphrase")
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateCredentials(t)
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			h, err := parseHost(tt.host)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			token, source, err := tt.auth.findToken(h)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("findToken error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if token != tt.wantToken {
+				t.Errorf("Expected token %q, got %q", tt.wantToken, token)
+			}
+			if !strings.Contains(source, tt.wantSource) {
+				t.Errorf("Expected the source to mention %q, got %q", tt.wantSource, source)
+			}
+		})
+	}
+}
+
+func TestAuth_FindToken_MissingCredentials(t *testing.T) {
+	isolateCredentials(t)
+	h, _ := parseHost("")
+
+	_, _, err := Auth{}.findToken(h)
+	var missingCredentials *MissingCredentialsError
+	if !errors.As(err, &missingCredentials) {
+		t.Fatalf("Expected a MissingCredentialsError, got %v", err)
+	}
+}
+
+func saveStore(t *testing.T, passphrase string, tokens map[string]string) {
+	t.Helper()
+	store, err := DefaultCredentialStore()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(passphrase, tokens); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
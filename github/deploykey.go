@@ -0,0 +1,166 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/ssh"
+)
+
+// DeployKey represents a GitHub repository deploy key destination.
+// The private key is generated by key-rotator and the public key is registered on the repository.
+type DeployKey struct {
+	Repo      string `yaml:"repo"`
+	Title     string `yaml:"title"`
+	ReadWrite bool   `yaml:"read_write"`
+}
+
+// GetDescription returns the destination description.
+func (d DeployKey) GetDescription() string {
+	access := "read-only"
+	if d.ReadWrite {
+		access = "read-write"
+	}
+	return fmt.Sprintf("%s GitHub Deploy Key (%s) in the %s repository", d.Title, access, d.Repo)
+}
+
+// Owner returns the owner of the repository.
+func (d DeployKey) Owner() string {
+	return repoOwner(d.Repo)
+}
+
+// GenerateSecret generates a new ed25519 private key in the OpenSSH PEM format.
+func (d DeployKey) GenerateSecret() (string, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %v", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(privateKey, d.Title)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %v", err)
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// UpdateSecret registers the public half of the private key as a deploy key,
+// then deletes any previous deploy key with the same title.
+func (d DeployKey) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(secretValue))
+	if err != nil {
+		return fmt.Errorf("failed to parse private key: %v", err)
+	}
+	publicKey := signer.PublicKey()
+
+	keys, err := client.listDeployKeys(ctx, owner, repo)
+	if err != nil {
+		return repositoryAPIError(d.Repo, err)
+	}
+
+	registered := false
+	var previous []*github.Key
+	for _, key := range keys {
+		if sameKey(key.GetKey(), publicKey) {
+			registered = true
+			continue
+		}
+		if key.GetTitle() == d.Title {
+			previous = append(previous, key)
+		}
+	}
+
+	// The key is valid and the deploy keys can be listed, which is all a dry run can verify.
+	if client.dryRun {
+		return nil
+	}
+
+	if !registered {
+		_, _, err = client.Repositories.CreateKey(ctx, owner, repo, &github.Key{
+			Title:    github.Ptr(d.Title),
+			Key:      github.Ptr(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))),
+			ReadOnly: github.Ptr(!d.ReadWrite),
+		})
+		if err != nil {
+			return apiError(d.Repo, fmt.Errorf("failed to create deploy key: %w", err))
+		}
+	}
+
+	for _, key := range previous {
+		if _, err := client.Repositories.DeleteKey(ctx, owner, repo, key.GetID()); err != nil {
+			return apiError(d.Repo, fmt.Errorf("failed to delete previous deploy key %d: %w", key.GetID(), err))
+		}
+	}
+	return nil
+}
+
+// Check verifies that the repository exists and that its deploy keys can be read.
+func (d DeployKey) Check(ctx context.Context, client Client) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+	_, err = client.listDeployKeys(ctx, owner, repo)
+	return repositoryAPIError(d.Repo, err)
+}
+
+// Status reports whether a deploy key with the title exists in the repository and when the newest one was created.
+// Deploy keys are replaced rather than updated, so their creation time is when they were last rotated.
+func (d DeployKey) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	keys, err := client.listDeployKeys(ctx, owner, repo)
+	if err != nil {
+		return SecretStatus{}, repositoryAPIError(d.Repo, err)
+	}
+
+	var status SecretStatus
+	for _, key := range keys {
+		if key.GetTitle() != d.Title {
+			continue
+		}
+		status.Exists = true
+		if createdAt := key.GetCreatedAt().Time; createdAt.After(status.UpdatedAt) {
+			status.UpdatedAt = createdAt
+		}
+	}
+	return status, nil
+}
+
+// listDeployKeys lists all the deploy keys in the repository.
+func (ghc Client) listDeployKeys(ctx context.Context, owner string, repo string) ([]*github.Key, error) {
+	var keys []*github.Key
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := ghc.Repositories.ListKeys(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deploy keys: %w", err)
+		}
+		keys = append(keys, page...)
+		if resp.NextPage == 0 {
+			return keys, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// sameKey reports whether an authorized key returned by GitHub matches the public key.
+func sameKey(authorizedKey string, publicKey ssh.PublicKey) bool {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(key.Marshal(), publicKey.Marshal())
+}
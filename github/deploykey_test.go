@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestDeployKey_GenerateSecret(t *testing.T) {
+	d := DeployKey{Repo: "o/r", Title: "ci"}
+	privateKey, err := d.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		t.Fatalf("Expected a valid private key, got %v", err)
+	}
+	if got := signer.PublicKey().Type(); got != ssh.KeyAlgoED25519 {
+		t.Errorf("Expected an %s key, got %s", ssh.KeyAlgoED25519, got)
+	}
+}
+
+func TestDeployKey_UpdateSecret_ReplacesPreviousKey(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	d := DeployKey{Repo: "o/r", Title: "ci"}
+	privateKey, err := d.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	previousKey, err := d.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r/keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, `[{"id":1,"title":"ci","key":%q},{"id":2,"title":"other","key":%q}]`, authorizedKey(t, previousKey), authorizedKey(t, previousKey))
+		case "POST":
+			var reqBody github.Key
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+				t.Fatalf("Failed to decode request body: %v", err)
+			}
+			if reqBody.GetKey() != authorizedKey(t, privateKey) {
+				t.Errorf("Expected key %s, got %s", authorizedKey(t, privateKey), reqBody.GetKey())
+			}
+			if !reqBody.GetReadOnly() {
+				t.Error("Expected a read-only deploy key")
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":3}`)
+		default:
+			t.Errorf("Unexpected request method %s", r.Method)
+		}
+	})
+
+	deleted := false
+	mux.HandleFunc("/repos/o/r/keys/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/repos/o/r/keys/2", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the deploy key with a different title to be kept")
+	})
+
+	ctx := context.Background()
+	err = d.UpdateSecret(ctx, client, privateKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !deleted {
+		t.Fatal("Expected the previous deploy key to be deleted")
+	}
+}
+
+func TestDeployKey_UpdateSecret_AlreadyRegistered(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	d := DeployKey{Repo: "o/r", Title: "ci", ReadWrite: true}
+	privateKey, err := d.GenerateSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r/keys", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprintf(w, `[{"id":1,"title":"ci","key":%q}]`, authorizedKey(t, privateKey))
+	})
+
+	ctx := context.Background()
+	err = d.UpdateSecret(ctx, client, privateKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestDeployKey_UpdateSecret_InvalidPrivateKey(t *testing.T) {
+	client, _, _ := setup(t)
+
+	ctx := context.Background()
+	d := DeployKey{Repo: "o/r", Title: "ci"}
+	err := d.UpdateSecret(ctx, client, "not a private key")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func authorizedKey(t *testing.T, privateKey string) string {
+	t.Helper()
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// maxValueSize is the largest value GitHub accepts for a secret or a variable.
+const maxValueSize = 48 * 1024
+
+// namePattern matches the names GitHub accepts for secrets and variables.
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DryRun returns a copy of the client that fetches everything an update needs,
+// such as public keys and repository IDs, but validates the requests that would
+// write secrets, variables, webhooks and deploy keys instead of sending them.
+func (ghc Client) DryRun() Client {
+	ghc.dryRun = true
+	return ghc
+}
+
+// validateSecret checks an encrypted secret the way GitHub would before storing it.
+func validateSecret(secret secret) error {
+	if err := validateName("secret", secret.Name); err != nil {
+		return err
+	}
+	if secret.KeyID == "" {
+		return fmt.Errorf("secret %s has no public key ID", secret.Name)
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(secret.EncryptedValue)
+	if err != nil {
+		return fmt.Errorf("secret %s is not base64 encoded: %v", secret.Name, err)
+	}
+	if len(encrypted) < box.AnonymousOverhead {
+		return fmt.Errorf("secret %s is not encrypted with a sealed box", secret.Name)
+	}
+	if size := len(encrypted) - box.AnonymousOverhead; size > maxValueSize {
+		return fmt.Errorf("secret %s is %d bytes, GitHub accepts at most %d", secret.Name, size, maxValueSize)
+	}
+	return validateVisibility(secret.Visibility, len(secret.SelectedRepositoryIDs))
+}
+
+// validateVariable checks a variable the way GitHub would before storing it.
+func validateVariable(variable *github.ActionsVariable) error {
+	if err := validateName("variable", variable.Name); err != nil {
+		return err
+	}
+	if size := len(variable.Value); size > maxValueSize {
+		return fmt.Errorf("variable %s is %d bytes, GitHub accepts at most %d", variable.Name, size, maxValueSize)
+	}
+	var selected int
+	if variable.SelectedRepositoryIDs != nil {
+		selected = len(*variable.SelectedRepositoryIDs)
+	}
+	return validateVisibility(variable.GetVisibility(), selected)
+}
+
+// validateName checks the name of a secret or variable.
+func validateName(kind string, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q, only letters, digits and underscores are allowed, and it must not start with a digit", kind, name)
+	}
+	if strings.HasPrefix(strings.ToUpper(name), "GITHUB_") {
+		return fmt.Errorf("invalid %s name %q, names must not start with GITHUB_", kind, name)
+	}
+	return nil
+}
+
+// validateVisibility checks the visibility of an organization secret or variable.
+// Repository secrets and variables have no visibility.
+func validateVisibility(visibility string, selectedRepositories int) error {
+	switch visibility {
+	case "", visibilityAll, visibilityPrivate:
+		return nil
+	case visibilitySelected:
+		if selectedRepositories == 0 {
+			return fmt.Errorf("the %s visibility requires at least one selected repository", visibilitySelected)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid visibility: %s", visibility)
+}
@@ -0,0 +1,202 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestDryRun_UpdateSecret(t *testing.T) {
+	public, _, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	publicKey := fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:]))
+
+	tests := []struct {
+		name        string
+		destination interface {
+			UpdateSecret(ctx context.Context, client Client, secretValue string) error
+		}
+		value       string
+		handlers    map[string]http.HandlerFunc
+		expectError bool
+	}{
+		{
+			name:        "repository secret",
+			destination: RepositorySecret{Repo: "o/r", Name: "MY_SECRET"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/actions/secrets/public-key": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, publicKey)
+				},
+			},
+		},
+		{
+			name:        "reserved secret name",
+			destination: RepositorySecret{Repo: "o/r", Name: "GITHUB_TOKEN"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/actions/secrets/public-key": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, publicKey)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "secret too large",
+			destination: OrganizationSecret{Org: "o", Name: "MY_SECRET"},
+			value:       strings.Repeat("a", maxValueSize+1),
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o/actions/secrets/public-key": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, publicKey)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "missing public key",
+			destination: DependabotRepositorySecret{Repo: "o/r", Name: "MY_SECRET"},
+			value:       "value",
+			expectError: true,
+		},
+		{
+			name:        "repository variable",
+			destination: RepositoryVariable{Repo: "o/r", Name: "MY_VARIABLE"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+				"GET /repos/o/r/actions/variables/MY_VARIABLE": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"name":"MY_VARIABLE","value":"old"}`)
+				},
+			},
+		},
+		{
+			name:        "repository variable that would be created",
+			destination: RepositoryVariable{Repo: "o/r", Name: "MY_VARIABLE"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+		},
+		{
+			name:        "repository variable in a missing repository",
+			destination: RepositoryVariable{Repo: "o/typo", Name: "MY_VARIABLE"},
+			value:       "value",
+			expectError: true,
+		},
+		{
+			name:        "repository variable without permission",
+			destination: RepositoryVariable{Repo: "o/r", Name: "MY_VARIABLE"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+				"GET /repos/o/r/actions/variables/MY_VARIABLE": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "environment variable in a missing environment",
+			destination: RepositoryEnvironmentVariable{Repo: "o/r", Environment: "typo", Name: "MY_VARIABLE"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "organization variable",
+			destination: OrganizationVariable{Org: "o", Name: "MY_VARIABLE"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"login":"o"}`)
+				},
+			},
+		},
+		{
+			name:        "organization variable in a missing organization",
+			destination: OrganizationVariable{Org: "typo", Name: "MY_VARIABLE"},
+			value:       "value",
+			expectError: true,
+		},
+		{
+			name:        "invalid variable name",
+			destination: RepositoryVariable{Repo: "o/r", Name: "1-variable"},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "repository webhook",
+			destination: RepositoryWebhook{Repo: "o/r", HookID: 1},
+			value:       "value",
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/hooks/1": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+		},
+		{
+			name:        "missing repository webhook",
+			destination: RepositoryWebhook{Repo: "o/r", HookID: 2},
+			value:       "value",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _ := setup(t)
+			for pattern, handler := range tt.handlers {
+				mux.HandleFunc(pattern, handler)
+			}
+			// Reads of anything else are not found, rather than rejected for their method.
+			mux.HandleFunc("GET /", http.NotFound)
+			// A dry run must never write anything.
+			for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+				mux.HandleFunc(method+" /", func(w http.ResponseWriter, r *http.Request) {
+					t.Errorf("Unexpected write %s %s", r.Method, r.URL.Path)
+				})
+			}
+
+			err := tt.destination.UpdateSecret(context.Background(), client.DryRun(), tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("UpdateSecret error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateVariable_Visibility(t *testing.T) {
+	v := &github.ActionsVariable{Name: "MY_VARIABLE", Visibility: github.Ptr(visibilitySelected)}
+	if err := validateVariable(v); err == nil {
+		t.Errorf("Expected an error for the selected visibility without repositories")
+	}
+
+	v.SelectedRepositoryIDs = &github.SelectedRepoIDs{1}
+	if err := validateVariable(v); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
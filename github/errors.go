@@ -0,0 +1,102 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// MissingCredentialsError is returned when no GitHub token is configured for a host.
+type MissingCredentialsError struct {
+	Host string
+	// Variables are the environment variables the token is read from, in order of precedence.
+	Variables []string
+}
+
+func (e *MissingCredentialsError) Error() string {
+	return fmt.Sprintf("no GitHub token found for %s in the %s environment variables, the gh CLI, a token helper or the credential store", e.Host, strings.Join(e.Variables, " or "))
+}
+
+// InvalidRepoFormatError is returned when a repository is not in the owner/repo format.
+type InvalidRepoFormatError struct {
+	Repo string
+}
+
+func (e *InvalidRepoFormatError) Error() string {
+	return fmt.Sprintf("invalid repository %q, expected the owner/repo format", e.Repo)
+}
+
+// RepoNotFoundError is returned when a repository does not exist, or is not visible to the token.
+type RepoNotFoundError struct {
+	Repo string
+	Err  error
+}
+
+func (e *RepoNotFoundError) Error() string {
+	return fmt.Sprintf("repository %s not found: %v", e.Repo, e.Err)
+}
+
+func (e *RepoNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// PermissionDeniedError is returned when the token is rejected or lacks access to a resource.
+type PermissionDeniedError struct {
+	// Resource is the repository, organization or user the request was made for.
+	Resource string
+	Err      error
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("permission denied for %s: %v", e.Resource, e.Err)
+}
+
+func (e *PermissionDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// KeyFetchError is returned when the public key used to encrypt a secret cannot be fetched.
+type KeyFetchError struct {
+	// Scope identifies the service and the repository, environment, organization or user of the key.
+	Scope string
+	Err   error
+}
+
+func (e *KeyFetchError) Error() string {
+	return fmt.Sprintf("failed to get public key for %s: %v", e.Scope, e.Err)
+}
+
+func (e *KeyFetchError) Unwrap() error {
+	return e.Err
+}
+
+// apiError converts an error returned by the GitHub API for the resource into a typed error.
+// Errors that are not permission errors are returned unchanged.
+func apiError(resource string, err error) error {
+	switch statusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &PermissionDeniedError{Resource: resource, Err: err}
+	}
+	return err
+}
+
+// repositoryAPIError converts an error returned by the GitHub API for the repository into a typed error.
+// GitHub responds with a 404 status both for missing repositories and for those the token cannot see.
+func repositoryAPIError(ownerRepo string, err error) error {
+	if statusCode(err) == http.StatusNotFound {
+		return &RepoNotFoundError{Repo: ownerRepo, Err: err}
+	}
+	return apiError(ownerRepo, err)
+}
+
+// statusCode returns the HTTP status of a GitHub API error, or 0 if there is none.
+func statusCode(err error) int {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewClient_MissingCredentials(t *testing.T) {
+	isolateCredentials(t)
+
+	_, _, err := NewClient("", Auth{})
+	var missingCredentials *MissingCredentialsError
+	if !errors.As(err, &missingCredentials) {
+		t.Fatalf("Expected a MissingCredentialsError, got %v", err)
+	}
+	if missingCredentials.Host != DefaultHost || missingCredentials.Variables[0] != "GITHUB_TOKEN" {
+		t.Errorf("Expected GITHUB_TOKEN for %s, got %v for %s", DefaultHost, missingCredentials.Variables, missingCredentials.Host)
+	}
+}
+
+func TestUpdateSecret_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination interface {
+			UpdateSecret(ctx context.Context, client Client, secretValue string) error
+		}
+		status int
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:        "invalid repository format",
+			destination: RepositorySecret{Repo: "invalid", Name: "mysecret"},
+			check: func(t *testing.T, err error) {
+				var target *InvalidRepoFormatError
+				if !errors.As(err, &target) || target.Repo != "invalid" {
+					t.Errorf("Expected an InvalidRepoFormatError for invalid, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "repository not found",
+			destination: RepositorySecret{Repo: "o/r", Name: "mysecret"},
+			status:      http.StatusNotFound,
+			check: func(t *testing.T, err error) {
+				var keyFetch *KeyFetchError
+				if !errors.As(err, &keyFetch) {
+					t.Errorf("Expected a KeyFetchError, got %v", err)
+				}
+				var target *RepoNotFoundError
+				if !errors.As(err, &target) || target.Repo != "o/r" {
+					t.Errorf("Expected a RepoNotFoundError for o/r, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "permission denied",
+			destination: OrganizationSecret{Org: "o", Name: "mysecret"},
+			status:      http.StatusForbidden,
+			check: func(t *testing.T, err error) {
+				var target *PermissionDeniedError
+				if !errors.As(err, &target) || target.Resource != "o" {
+					t.Errorf("Expected a PermissionDeniedError for o, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "environment repository not found",
+			destination: RepositoryEnvironmentSecret{Repo: "o/r", Name: "mysecret", Environment: "env"},
+			status:      http.StatusNotFound,
+			check: func(t *testing.T, err error) {
+				var target *RepoNotFoundError
+				if !errors.As(err, &target) || target.Repo != "o/r" {
+					t.Errorf("Expected a RepoNotFoundError for o/r, got %v", err)
+				}
+			},
+		},
+		{
+			name:        "other API errors are not classified",
+			destination: RepositorySecret{Repo: "o/r", Name: "mysecret"},
+			status:      http.StatusUnprocessableEntity,
+			check: func(t *testing.T, err error) {
+				var repoNotFound *RepoNotFoundError
+				var permissionDenied *PermissionDeniedError
+				if err == nil || errors.As(err, &repoNotFound) || errors.As(err, &permissionDenied) {
+					t.Errorf("Expected an unclassified error, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _ := setup(t)
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			err := tt.destination.UpdateSecret(context.Background(), client, "mysecretvalue")
+			tt.check(t, err)
+		})
+	}
+}
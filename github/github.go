@@ -5,33 +5,92 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v69/github"
 	"golang.org/x/crypto/nacl/box"
 )
 
-// GitHub secret destination types.
+// GitHub destination types.
 const (
-	TypeGitHubRepository            = "github-repository"
-	TypeGitHubRepositoryDependabot  = "github-repository-dependabot"
-	TypeGitHubRepositoryEnvironment = "github-repository-environment"
+	TypeGitHubRepository             = "github-repository"
+	TypeGitHubRepositoryDependabot   = "github-repository-dependabot"
+	TypeGitHubRepositoryEnvironment  = "github-repository-environment"
+	TypeGitHubOrganization           = "github-organization"
+	TypeGitHubOrganizationDependabot = "github-organization-dependabot"
+	TypeGitHubRepositoryCodespaces   = "github-repository-codespaces"
+	TypeGitHubOrganizationCodespaces = "github-organization-codespaces"
+	TypeGitHubUserCodespaces         = "github-user-codespaces"
+
+	TypeGitHubRepositoryVariable            = "github-repository-variable"
+	TypeGitHubRepositoryEnvironmentVariable = "github-repository-environment-variable"
+	TypeGitHubOrganizationVariable          = "github-organization-variable"
+
+	TypeGitHubRepositoryWebhook   = "github-repository-webhook"
+	TypeGitHubOrganizationWebhook = "github-organization-webhook"
+
+	TypeGitHubDeployKey = "github-deploy-key"
 )
 
 // Client wraps the GitHub client.
 type Client struct {
 	*github.Client
+	// cache is shared by copies of the client for the duration of a run.
+	cache *cache
+	// dryRun validates writes instead of sending them.
+	dryRun bool
 }
 
-// NewClient creates a new GitHub client with authentication.
-func NewClient() Client {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		log.Fatalf("The GITHUB_TOKEN environment variable must be set")
+// NewClient creates a new GitHub client authenticated with a token for the host,
+// and returns a description of where the token was found.
+// The host is a hostname such as github.example.com or an API base URL;
+// an empty host targets github.com.
+func NewClient(host string, auth Auth) (Client, string, error) {
+	server, err := parseHost(host)
+	if err != nil {
+		return Client{}, "", err
+	}
+
+	token, source, err := auth.findToken(server)
+	if err != nil {
+		return Client{}, "", err
 	}
-	return Client{github.NewClient(nil).WithAuthToken(token)}
+
+	c, err := newGitHubClient(server, retryingTransport())
+	if err != nil {
+		return Client{}, "", err
+	}
+	return newClient(c.WithAuthToken(token)), source, nil
+}
+
+// newClient wraps the GitHub client with an empty cache.
+func newClient(c *github.Client) Client {
+	return Client{Client: c, cache: newCache()}
+}
+
+// newGitHubClient creates a GitHub client for the host that sends its requests through the transport.
+func newGitHubClient(server host, transport http.RoundTripper) (*github.Client, error) {
+	c := github.NewClient(&http.Client{Transport: transport})
+	if server.isDotCom() {
+		return c, nil
+	}
+	c, err := c.WithEnterpriseURLs(server.baseURL, server.uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %s: %v", server.name, err)
+	}
+	return c, nil
+}
+
+// retryingTransport returns a transport that retries transient errors and rate-limited requests.
+func retryingTransport() http.RoundTripper {
+	transport := newRetryTransport(http.DefaultTransport)
+	transport.onRetry = func(req *http.Request, reason string, wait time.Duration) {
+		fmt.Fprintf(os.Stderr, "GitHub %s on %s %s, retrying in %s\n", reason, req.Method, req.URL.Path, wait.Round(time.Second))
+	}
+	return transport
 }
 
 // RepositorySecret represents a GitHub repository secret destination.
@@ -42,15 +101,14 @@ type RepositorySecret struct {
 
 // UpdateSecret updates the GitHub Actions secret in the repository.
 func (d RepositorySecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
-	ownerRepo := strings.Split(d.Repo, "/")
-	if len(ownerRepo) != 2 {
-		return fmt.Errorf("invalid destination format: %s", d.Repo)
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
 	}
-	owner, repo := ownerRepo[0], ownerRepo[1]
 
-	key, _, err := client.Actions.GetRepoPublicKey(ctx, owner, repo)
+	key, err := d.publicKey(ctx, client)
 	if err != nil {
-		return fmt.Errorf("failed to get public key: %v", err)
+		return err
 	}
 
 	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
@@ -64,7 +122,41 @@ func (d RepositorySecret) UpdateSecret(ctx context.Context, client Client, secre
 		EncryptedValue: encryptedValue,
 	}
 
-	return client.updateRepositorySecret(ctx, owner, repo, ghSecret)
+	return repositoryAPIError(d.Repo, client.updateRepositorySecret(ctx, owner, repo, ghSecret))
+}
+
+// Check verifies that the repository exists and that its public key can be read.
+func (d RepositorySecret) Check(ctx context.Context, client Client) error {
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the repository and when it was last updated.
+func (d RepositorySecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Actions.GetRepoSecret(ctx, owner, repo, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// publicKey returns the public key used to encrypt GitHub Actions secrets in the repository.
+func (d RepositorySecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.publicKey(cacheKey("actions", "repos", owner, repo), func() (*github.PublicKey, error) {
+		key, _, err := client.Actions.GetRepoPublicKey(ctx, owner, repo)
+		return key, repositoryAPIError(d.Repo, err)
+	})
 }
 
 // GetDescription returns the destination description.
@@ -72,6 +164,11 @@ func (d RepositorySecret) GetDescription() string {
 	return fmt.Sprintf("%s GitHub Repository Secret in the %s repository", d.Name, d.Repo)
 }
 
+// Owner returns the owner of the repository.
+func (d RepositorySecret) Owner() string {
+	return repoOwner(d.Repo)
+}
+
 // DependabotRepositorySecret represents a GitHub Dependabot secret destination.
 type DependabotRepositorySecret struct {
 	Repo string `yaml:"repo"`
@@ -83,17 +180,21 @@ func (d DependabotRepositorySecret) GetDescription() string {
 	return fmt.Sprintf("%s GitHub Dependabot Repository Secret in the %s repository", d.Name, d.Repo)
 }
 
+// Owner returns the owner of the repository.
+func (d DependabotRepositorySecret) Owner() string {
+	return repoOwner(d.Repo)
+}
+
 // UpdateSecret updates the Dependabot secret in the repository.
 func (d DependabotRepositorySecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
-	ownerRepo := strings.Split(d.Repo, "/")
-	if len(ownerRepo) != 2 {
-		return fmt.Errorf("invalid destination format: %s", d.Repo)
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
 	}
-	owner, repo := ownerRepo[0], ownerRepo[1]
 
-	key, _, err := client.Dependabot.GetRepoPublicKey(ctx, owner, repo)
+	key, err := d.publicKey(ctx, client)
 	if err != nil {
-		return fmt.Errorf("failed to get public key: %v", err)
+		return err
 	}
 
 	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
@@ -107,7 +208,41 @@ func (d DependabotRepositorySecret) UpdateSecret(ctx context.Context, client Cli
 		EncryptedValue: encryptedValue,
 	}
 
-	return client.updateDependabotSecret(ctx, owner, repo, ghSecret)
+	return repositoryAPIError(d.Repo, client.updateDependabotSecret(ctx, owner, repo, ghSecret))
+}
+
+// Check verifies that the repository exists and that its public key can be read.
+func (d DependabotRepositorySecret) Check(ctx context.Context, client Client) error {
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the repository and when it was last updated.
+func (d DependabotRepositorySecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Dependabot.GetRepoSecret(ctx, owner, repo, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// publicKey returns the public key used to encrypt Dependabot secrets in the repository.
+func (d DependabotRepositorySecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.publicKey(cacheKey("dependabot", "repos", owner, repo), func() (*github.PublicKey, error) {
+		key, _, err := client.Dependabot.GetRepoPublicKey(ctx, owner, repo)
+		return key, repositoryAPIError(d.Repo, err)
+	})
 }
 
 // RepositoryEnvironmentSecret represents a GitHub environment secret destination.
@@ -122,22 +257,26 @@ func (d RepositoryEnvironmentSecret) GetDescription() string {
 	return fmt.Sprintf("%s GitHub Repository Environment Secret in the %s repository's %s environment", d.Name, d.Repo, d.Environment)
 }
 
+// Owner returns the owner of the repository.
+func (d RepositoryEnvironmentSecret) Owner() string {
+	return repoOwner(d.Repo)
+}
+
 // UpdateSecret updates the GitHub Actions environment secret in the repository.
 func (d RepositoryEnvironmentSecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
-	ownerRepo := strings.Split(d.Repo, "/")
-	if len(ownerRepo) != 2 {
-		return fmt.Errorf("invalid destination format: %s", d.Repo)
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
 	}
-	owner, repo := ownerRepo[0], ownerRepo[1]
 
-	repository, _, err := client.Repositories.Get(ctx, owner, repo)
+	repositoryID, err := client.repositoryID(ctx, owner, repo)
 	if err != nil {
-		return fmt.Errorf("failed to get repository: %v", err)
+		return err
 	}
 
-	key, _, err := client.Actions.GetEnvPublicKey(ctx, int(repository.GetID()), d.Environment)
+	key, err := d.publicKey(ctx, client, repositoryID)
 	if err != nil {
-		return fmt.Errorf("failed to get public key: %v", err)
+		return err
 	}
 
 	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
@@ -151,11 +290,61 @@ func (d RepositoryEnvironmentSecret) UpdateSecret(ctx context.Context, client Cl
 		EncryptedValue: encryptedValue,
 	}
 
-	return client.updateEnvironmentSecret(ctx, owner, repo, d.Environment, ghSecret)
+	return apiError(d.Repo, client.updateEnvironmentSecret(ctx, repositoryID, d.Environment, ghSecret))
+}
+
+// Check verifies that the repository and its environment exist, and that the public key of the environment can be read.
+func (d RepositoryEnvironmentSecret) Check(ctx context.Context, client Client) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	repositoryID, err := client.repositoryID(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	if err := client.checkEnvironment(ctx, owner, repo, d.Environment); err != nil {
+		return err
+	}
+	_, err = d.publicKey(ctx, client, repositoryID)
+	return err
+}
+
+// Status reports whether the secret exists in the environment and when it was last updated.
+func (d RepositoryEnvironmentSecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	repositoryID, err := client.repositoryID(ctx, owner, repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Actions.GetEnvSecret(ctx, int(repositoryID), d.Environment, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// publicKey returns the public key used to encrypt GitHub Actions secrets in the environment.
+func (d RepositoryEnvironmentSecret) publicKey(ctx context.Context, client Client, repositoryID int64) (*github.PublicKey, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.publicKey(cacheKey("actions", "repos", owner, repo, "environments", d.Environment), func() (*github.PublicKey, error) {
+		key, _, err := client.Actions.GetEnvPublicKey(ctx, int(repositoryID), d.Environment)
+		return key, apiError(d.Repo, err)
+	})
 }
 
 // updateRepositorySecret updates a GitHub Actions secret in the repository.
 func (ghc Client) updateRepositorySecret(ctx context.Context, owner string, repo string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
 	s := &github.EncryptedSecret{
 		Name:           secret.Name,
 		KeyID:          secret.KeyID,
@@ -167,6 +356,9 @@ func (ghc Client) updateRepositorySecret(ctx context.Context, owner string, repo
 
 // updateDependabotSecret updates a GitHub Dependabot secret in the repository.
 func (ghc Client) updateDependabotSecret(ctx context.Context, owner string, repo string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
 	s := &github.DependabotEncryptedSecret{
 		Name:           secret.Name,
 		KeyID:          secret.KeyID,
@@ -177,18 +369,16 @@ func (ghc Client) updateDependabotSecret(ctx context.Context, owner string, repo
 }
 
 // updateEnvironmentSecret updates a GitHub environment secret in the repository.
-func (ghc Client) updateEnvironmentSecret(ctx context.Context, owner string, repo string, environment string, secret secret) error {
-	repository, _, err := ghc.Repositories.Get(ctx, owner, repo)
-	if err != nil {
-		log.Fatalf("Failed to get repository: %v", err)
+func (ghc Client) updateEnvironmentSecret(ctx context.Context, repositoryID int64, environment string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
 	}
-
 	s := &github.EncryptedSecret{
 		Name:           secret.Name,
 		KeyID:          secret.KeyID,
 		EncryptedValue: secret.EncryptedValue,
 	}
-	_, err = ghc.Actions.CreateOrUpdateEnvSecret(ctx, int(repository.GetID()), environment, s)
+	_, err := ghc.Actions.CreateOrUpdateEnvSecret(ctx, int(repositoryID), environment, s)
 	return err
 }
 
@@ -197,6 +387,27 @@ type secret struct {
 	Name           string
 	KeyID          string
 	EncryptedValue string
+	// Visibility and SelectedRepositoryIDs only apply to organization secrets.
+	Visibility            string
+	SelectedRepositoryIDs []int64
+}
+
+// splitRepo splits a repository in the owner/repo format into its owner and name.
+func splitRepo(ownerRepo string) (string, string, error) {
+	parts := strings.Split(ownerRepo, "/")
+	if len(parts) != 2 {
+		return "", "", &InvalidRepoFormatError{Repo: ownerRepo}
+	}
+	return parts[0], parts[1], nil
+}
+
+// repoOwner returns the owner of a repository in the owner/repo format, or an empty string if it is invalid.
+func repoOwner(ownerRepo string) string {
+	owner, _, err := splitRepo(ownerRepo)
+	if err != nil {
+		return ""
+	}
+	return owner
 }
 
 // encryptSodiumSecret encrypts a secret value using a public key.
@@ -229,7 +229,7 @@ func setup(t *testing.T) (Client, *http.ServeMux, string) {
 	c.BaseURL = baseUrl
 	c.UploadURL = baseUrl
 
-	client := Client{c}
+	client := newClient(c)
 
 	t.Cleanup(server.Close)
 
@@ -0,0 +1,220 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// DefaultHost is the host used when none is configured.
+const DefaultHost = "github.com"
+
+// host is a parsed GitHub host.
+type host struct {
+	// name is the hostname, e.g. github.com or github.example.com.
+	name      string
+	baseURL   string
+	uploadURL string
+}
+
+// parseHost parses a hostname, such as github.example.com, or an API base URL,
+// such as https://github.example.com/api/v3/.
+func parseHost(value string) (host, error) {
+	if value == "" {
+		return host{name: DefaultHost}, nil
+	}
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return host{}, fmt.Errorf("invalid host %q, expected a hostname or an API base URL", value)
+	}
+	return host{
+		name:      strings.ToLower(u.Hostname()),
+		baseURL:   value,
+		uploadURL: u.Scheme + "://" + u.Host + "/",
+	}, nil
+}
+
+// isDotCom reports whether the host is github.com.
+func (h host) isDotCom() bool {
+	return h.name == DefaultHost || h.name == "api.github.com"
+}
+
+// tokenVariables returns the environment variables the token for the host is read from, in order of precedence.
+// GitHub Enterprise Server tokens can be set per host, e.g. GITHUB_TOKEN_GITHUB_EXAMPLE_COM,
+// or for every host with the variables also used by the gh CLI.
+func (h host) tokenVariables() []string {
+	if h.isDotCom() {
+		return []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	}
+	perHost := "GITHUB_TOKEN_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, h.name)
+	return []string{perHost, "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+}
+
+// Clients creates and holds the authenticated clients used by a configuration:
+// one per host with token authentication, or one per host and installation with GitHub App authentication.
+type Clients struct {
+	app *AppCredentials
+	// apps holds the client authenticated as the app itself for each host, built once by NewClients.
+	apps map[string]*github.Client
+
+	mu      sync.Mutex
+	clients map[string]Client
+	// sources describes where the token of each host was found.
+	sources map[string]string
+	// installations discovers the installation ID of each host and owner once,
+	// outside of mu so that parallel updates do not wait for each other's lookups.
+	installations map[string]func() (int64, error)
+}
+
+// NewClients prepares the clients for the hosts, authenticating with the GitHub App if one is given
+// and with tokens otherwise. Missing credentials are reported before any destination is updated.
+func NewClients(hosts []string, auth Auth) (*Clients, error) {
+	app := auth.App
+	c := &Clients{
+		app:           app,
+		apps:          map[string]*github.Client{},
+		clients:       map[string]Client{},
+		sources:       map[string]string{},
+		installations: map[string]func() (int64, error){},
+	}
+
+	if app != nil {
+		if err := app.Validate(); err != nil {
+			return nil, err
+		}
+		key, err := app.readPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hosts {
+			server, err := parseHost(h)
+			if err != nil {
+				return nil, err
+			}
+			if c.apps[h], err = newGitHubClient(server, &appTransport{base: retryingTransport(), appID: app.AppID, key: key}); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
+	}
+
+	// Only ask for the passphrase of the credential store once, whatever the number of hosts.
+	if auth.Passphrase != nil {
+		auth.Passphrase = sync.OnceValues(auth.Passphrase)
+	}
+	for _, h := range hosts {
+		if _, ok := c.clients[h]; ok {
+			continue
+		}
+		client, source, err := NewClient(h, auth)
+		if err != nil {
+			return nil, err
+		}
+		c.clients[h] = client
+		c.sources[h] = source
+	}
+	return c, nil
+}
+
+// Source describes how the clients for the host authenticate.
+func (c *Clients) Source(host string) string {
+	if c.app != nil {
+		return fmt.Sprintf("GitHub App %d", c.app.AppID)
+	}
+	return c.sources[host]
+}
+
+// Get returns the client for a destination on the host that belongs to the owner.
+// The owner is only used to discover the installation with GitHub App authentication.
+func (c *Clients) Get(ctx context.Context, host string, owner string) (Client, error) {
+	if c.app != nil {
+		return c.installationClient(ctx, host, owner)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, ok := c.clients[host]
+	if !ok {
+		return Client{}, fmt.Errorf("no client for host %s", host)
+	}
+	return client, nil
+}
+
+// installationClient returns the client authenticated as the app installation for the owner.
+// Its token is only created by the first request it sends.
+func (c *Clients) installationClient(ctx context.Context, host string, owner string) (Client, error) {
+	app, ok := c.apps[host]
+	if !ok {
+		return Client{}, fmt.Errorf("no client for host %s", host)
+	}
+	server, err := parseHost(host)
+	if err != nil {
+		return Client{}, err
+	}
+
+	installationID := c.app.InstallationID
+	if installationID == 0 {
+		if owner == "" {
+			return Client{}, fmt.Errorf("the GitHub App installation cannot be discovered for a destination without an owner, set installation_id")
+		}
+		if installationID, err = c.installation(ctx, app, server.name, owner); err != nil {
+			return Client{}, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clientKey := cacheKey(server.name, strconv.FormatInt(installationID, 10))
+	if client, ok := c.clients[clientKey]; ok {
+		return client, nil
+	}
+	gh, err := newGitHubClient(server, &installationTransport{base: retryingTransport(), app: app, installationID: installationID})
+	if err != nil {
+		return Client{}, err
+	}
+	client := newClient(gh)
+	c.clients[clientKey] = client
+	return client, nil
+}
+
+// installation returns the ID of the app installation for the owner on the host.
+// It is looked up once, and concurrent callers for the same owner wait for that lookup.
+func (c *Clients) installation(ctx context.Context, app *github.Client, hostName string, owner string) (int64, error) {
+	key := cacheKey(hostName, owner)
+	c.mu.Lock()
+	find, ok := c.installations[key]
+	if !ok {
+		find = sync.OnceValues(func() (int64, error) {
+			return findInstallation(ctx, app, owner)
+		})
+		c.installations[key] = find
+	}
+	c.mu.Unlock()
+	return find()
+}
+
+// HostName returns the hostname of a host setting, as used to look up its token.
+func HostName(value string) (string, error) {
+	h, err := parseHost(value)
+	if err != nil {
+		return "", err
+	}
+	return h.name, nil
+}
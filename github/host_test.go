@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseHost(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expectError bool
+		want        host
+	}{
+		{
+			name:  "Default host",
+			value: "",
+			want:  host{name: DefaultHost},
+		},
+		{
+			name:  "Hostname",
+			value: "GitHub.Example.com",
+			want:  host{name: "github.example.com", baseURL: "https://GitHub.Example.com", uploadURL: "https://GitHub.Example.com/"},
+		},
+		{
+			name:  "Base URL with a port",
+			value: "http://ghes.internal:8080/api/v3/",
+			want:  host{name: "ghes.internal", baseURL: "http://ghes.internal:8080/api/v3/", uploadURL: "http://ghes.internal:8080/"},
+		},
+		{
+			name:        "Invalid URL",
+			value:       "https://",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHost(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseHost error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && !cmp.Equal(got, tt.want, cmp.AllowUnexported(host{})) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHost_TokenVariables(t *testing.T) {
+	dotCom, _ := parseHost("")
+	if got, want := dotCom.tokenVariables(), []string{"GITHUB_TOKEN", "GH_TOKEN"}; !cmp.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	enterprise, _ := parseHost("ghes-1.example.com")
+	want := []string{"GITHUB_TOKEN_GHES_1_EXAMPLE_COM", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	if got := enterprise.tokenVariables(); !cmp.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestNewClients(t *testing.T) {
+	isolateCredentials(t)
+	t.Setenv("GITHUB_TOKEN", "dotcom-token")
+	t.Setenv("GITHUB_TOKEN_GITHUB_EXAMPLE_COM", "enterprise-token")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+
+	clients, err := NewClients([]string{"", "github.example.com", "https://github.example.com/api/v3/"}, Auth{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx := context.Background()
+	dotCom, err := clients.Get(ctx, "", "octo")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := dotCom.BaseURL.String(); got != "https://api.github.com/" {
+		t.Errorf("Expected the github.com API, got %s", got)
+	}
+
+	for _, h := range []string{"github.example.com", "https://github.example.com/api/v3/"} {
+		enterprise, err := clients.Get(ctx, h, "octo")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := enterprise.BaseURL.String(); got != "https://github.example.com/api/v3/" {
+			t.Errorf("Expected the enterprise API for %s, got %s", h, got)
+		}
+	}
+
+	if _, err := clients.Get(ctx, "other.example.com", "octo"); err == nil {
+		t.Errorf("Expected an error for a host without a client")
+	}
+
+	_, err = NewClients([]string{"other.example.com"}, Auth{})
+	var missingCredentials *MissingCredentialsError
+	if !errors.As(err, &missingCredentials) || missingCredentials.Host != "other.example.com" {
+		t.Errorf("Expected a MissingCredentialsError for other.example.com, got %v", err)
+	}
+}
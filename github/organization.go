@@ -0,0 +1,265 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// Organization secret visibility levels.
+const (
+	visibilityAll      = "all"
+	visibilityPrivate  = "private"
+	visibilitySelected = "selected"
+)
+
+// OrganizationSecret represents a GitHub organization secret destination.
+type OrganizationSecret struct {
+	Org                  string   `yaml:"org"`
+	Name                 string   `yaml:"name"`
+	Visibility           string   `yaml:"visibility"`
+	SelectedRepositories []string `yaml:"selected_repositories"`
+}
+
+// GetDescription returns the destination description.
+func (d OrganizationSecret) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Organization Secret in the %s organization", d.Name, d.Org)
+}
+
+// Owner returns the organization.
+func (d OrganizationSecret) Owner() string {
+	return d.Org
+}
+
+// Validate checks the visibility against the selected repositories.
+func (d OrganizationSecret) Validate() error {
+	return validateOrganizationVisibility(d.Visibility, d.SelectedRepositories)
+}
+
+// UpdateSecret updates the GitHub Actions secret in the organization.
+func (d OrganizationSecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	visibility := organizationVisibility(d.Visibility, d.SelectedRepositories)
+
+	repositoryIDs, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.publicKey(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %v", err)
+	}
+
+	ghSecret := secret{
+		Name:                  d.Name,
+		KeyID:                 key.GetKeyID(),
+		EncryptedValue:        encryptedValue,
+		Visibility:            visibility,
+		SelectedRepositoryIDs: repositoryIDs,
+	}
+
+	return apiError(d.Org, client.updateOrganizationSecret(ctx, d.Org, ghSecret))
+}
+
+// Check verifies the visibility and the selected repositories, and that the public key of the organization can be read.
+func (d OrganizationSecret) Check(ctx context.Context, client Client) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories); err != nil {
+		return err
+	}
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the organization and when it was last updated.
+func (d OrganizationSecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Actions.GetOrgSecret(ctx, d.Org, d.Name)
+	})
+	return status, apiError(d.Org, err)
+}
+
+// publicKey returns the public key used to encrypt GitHub Actions secrets in the organization.
+func (d OrganizationSecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	return client.publicKey(cacheKey("actions", "orgs", d.Org), func() (*github.PublicKey, error) {
+		key, _, err := client.Actions.GetOrgPublicKey(ctx, d.Org)
+		return key, apiError(d.Org, err)
+	})
+}
+
+// DependabotOrganizationSecret represents a GitHub organization Dependabot secret destination.
+type DependabotOrganizationSecret struct {
+	Org                  string   `yaml:"org"`
+	Name                 string   `yaml:"name"`
+	Visibility           string   `yaml:"visibility"`
+	SelectedRepositories []string `yaml:"selected_repositories"`
+}
+
+// GetDescription returns the destination description.
+func (d DependabotOrganizationSecret) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Dependabot Organization Secret in the %s organization", d.Name, d.Org)
+}
+
+// Owner returns the organization.
+func (d DependabotOrganizationSecret) Owner() string {
+	return d.Org
+}
+
+// Validate checks the visibility against the selected repositories.
+func (d DependabotOrganizationSecret) Validate() error {
+	return validateOrganizationVisibility(d.Visibility, d.SelectedRepositories)
+}
+
+// UpdateSecret updates the Dependabot secret in the organization.
+func (d DependabotOrganizationSecret) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	visibility := organizationVisibility(d.Visibility, d.SelectedRepositories)
+
+	repositoryIDs, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.publicKey(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	encryptedValue, err := encryptSodiumSecret(secretValue, key.GetKey())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret: %v", err)
+	}
+
+	ghSecret := secret{
+		Name:                  d.Name,
+		KeyID:                 key.GetKeyID(),
+		EncryptedValue:        encryptedValue,
+		Visibility:            visibility,
+		SelectedRepositoryIDs: repositoryIDs,
+	}
+
+	return apiError(d.Org, client.updateDependabotOrganizationSecret(ctx, d.Org, ghSecret))
+}
+
+// Check verifies the visibility and the selected repositories, and that the public key of the organization can be read.
+func (d DependabotOrganizationSecret) Check(ctx context.Context, client Client) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories); err != nil {
+		return err
+	}
+	_, err := d.publicKey(ctx, client)
+	return err
+}
+
+// Status reports whether the secret exists in the organization and when it was last updated.
+func (d DependabotOrganizationSecret) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	status, err := secretStatus(func() (*github.Secret, *github.Response, error) {
+		return client.Dependabot.GetOrgSecret(ctx, d.Org, d.Name)
+	})
+	return status, apiError(d.Org, err)
+}
+
+// publicKey returns the public key used to encrypt Dependabot secrets in the organization.
+func (d DependabotOrganizationSecret) publicKey(ctx context.Context, client Client) (*github.PublicKey, error) {
+	return client.publicKey(cacheKey("dependabot", "orgs", d.Org), func() (*github.PublicKey, error) {
+		key, _, err := client.Dependabot.GetOrgPublicKey(ctx, d.Org)
+		return key, apiError(d.Org, err)
+	})
+}
+
+// updateOrganizationSecret updates a GitHub Actions secret in the organization.
+func (ghc Client) updateOrganizationSecret(ctx context.Context, org string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
+	s := &github.EncryptedSecret{
+		Name:                  secret.Name,
+		KeyID:                 secret.KeyID,
+		EncryptedValue:        secret.EncryptedValue,
+		Visibility:            secret.Visibility,
+		SelectedRepositoryIDs: secret.SelectedRepositoryIDs,
+	}
+	_, err := ghc.Actions.CreateOrUpdateOrgSecret(ctx, org, s)
+	return err
+}
+
+// updateDependabotOrganizationSecret updates a GitHub Dependabot secret in the organization.
+func (ghc Client) updateDependabotOrganizationSecret(ctx context.Context, org string, secret secret) error {
+	if ghc.dryRun {
+		return validateSecret(secret)
+	}
+	s := &github.DependabotEncryptedSecret{
+		Name:                  secret.Name,
+		KeyID:                 secret.KeyID,
+		EncryptedValue:        secret.EncryptedValue,
+		Visibility:            secret.Visibility,
+		SelectedRepositoryIDs: secret.SelectedRepositoryIDs,
+	}
+	_, err := ghc.Dependabot.CreateOrUpdateOrgSecret(ctx, org, s)
+	return err
+}
+
+// repositoryIDs resolves repository names to their IDs.
+// When an organization is provided, names without an owner are assumed to belong to it.
+func (ghc Client) repositoryIDs(ctx context.Context, org string, repositories []string) ([]int64, error) {
+	var ids []int64
+	for _, name := range repositories {
+		ownerRepo := name
+		if org != "" && !strings.Contains(name, "/") {
+			ownerRepo = org + "/" + name
+		}
+		owner, repo, err := splitRepo(ownerRepo)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := ghc.repositoryID(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// validateOrganizationVisibility checks the visibility of an organization secret or variable against its selected repositories.
+// An empty visibility is valid, see organizationVisibility.
+func validateOrganizationVisibility(visibility string, selectedRepositories []string) error {
+	switch visibility {
+	case "":
+	case visibilityAll, visibilityPrivate:
+		if len(selectedRepositories) > 0 {
+			return fmt.Errorf("selected_repositories requires the %s visibility, got %s", visibilitySelected, visibility)
+		}
+	case visibilitySelected:
+		if len(selectedRepositories) == 0 {
+			return fmt.Errorf("the %s visibility requires at least one entry in selected_repositories", visibilitySelected)
+		}
+	default:
+		return fmt.Errorf("invalid visibility: %s", visibility)
+	}
+	return nil
+}
+
+// organizationVisibility returns the visibility of an organization secret or variable.
+// When no visibility is configured, it defaults to selected if repositories
+// are listed and private otherwise.
+func organizationVisibility(visibility string, selectedRepositories []string) string {
+	if visibility != "" {
+		return visibility
+	}
+	if len(selectedRepositories) > 0 {
+		return visibilitySelected
+	}
+	return visibilityPrivate
+}
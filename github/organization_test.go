@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v69/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestOrganizationSecret_UpdateSecret_SelectedRepositories(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	mux.HandleFunc("/repos/other/r2", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":2}`)
+	})
+
+	mux.HandleFunc("/orgs/o/actions/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:])))
+	})
+
+	mux.HandleFunc("/orgs/o/actions/secrets/mysecret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var reqBody github.EncryptedSecret
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.Visibility != "selected" {
+			t.Errorf("Expected visibility selected, got %s", reqBody.Visibility)
+		}
+		if diff := cmp.Diff(github.SelectedRepoIDs{1, 2}, reqBody.SelectedRepositoryIDs); diff != "" {
+			t.Errorf("Unexpected selected repository IDs (-want +got):\n%s", diff)
+		}
+		validateSodiumSecret(t, "mysecretvalue", reqBody.EncryptedValue, public, private)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	s := OrganizationSecret{
+		Org:                  "o",
+		Name:                 "mysecret",
+		SelectedRepositories: []string{"r1", "other/r2"},
+	}
+	err = s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestDependabotOrganizationSecret_UpdateSecret_SelectedRepositories(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mux.HandleFunc("/repos/o/r1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	mux.HandleFunc("/orgs/o/dependabot/secrets/public-key", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, fmt.Sprintf(`{"key_id":"1234","key":"%s"}`, base64.StdEncoding.EncodeToString(public[:])))
+	})
+
+	mux.HandleFunc("/orgs/o/dependabot/secrets/mysecret", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		var reqBody struct {
+			KeyID                 string   `json:"key_id"`
+			EncryptedValue        string   `json:"encrypted_value"`
+			Visibility            string   `json:"visibility"`
+			SelectedRepositoryIDs []string `json:"selected_repository_ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.Visibility != "selected" {
+			t.Errorf("Expected visibility selected, got %s", reqBody.Visibility)
+		}
+		if diff := cmp.Diff([]string{"1"}, reqBody.SelectedRepositoryIDs); diff != "" {
+			t.Errorf("Unexpected selected repository IDs (-want +got):\n%s", diff)
+		}
+		validateSodiumSecret(t, "mysecretvalue", reqBody.EncryptedValue, public, private)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	s := DependabotOrganizationSecret{
+		Org:                  "o",
+		Name:                 "mysecret",
+		Visibility:           "selected",
+		SelectedRepositories: []string{"r1"},
+	}
+	err = s.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func Test_organizationVisibility(t *testing.T) {
+	tests := []struct {
+		name                 string
+		visibility           string
+		selectedRepositories []string
+		want                 string
+		expectError          bool
+	}{
+		{
+			name: "default without repositories",
+			want: "private",
+		},
+		{
+			name:                 "default with repositories",
+			selectedRepositories: []string{"repo"},
+			want:                 "selected",
+		},
+		{
+			name:       "all",
+			visibility: "all",
+			want:       "all",
+		},
+		{
+			name:                 "all with repositories",
+			visibility:           "all",
+			selectedRepositories: []string{"repo"},
+			expectError:          true,
+		},
+		{
+			name:        "selected without repositories",
+			visibility:  "selected",
+			expectError: true,
+		},
+		{
+			name:        "unknown visibility",
+			visibility:  "public",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOrganizationVisibility(tt.visibility, tt.selectedRepositories)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("validateOrganizationVisibility() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+			if got := organizationVisibility(tt.visibility, tt.selectedRepositories); got != tt.want {
+				t.Errorf("organizationVisibility() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is the number of times a request is retried before its last response is returned.
+	defaultMaxRetries = 5
+	// defaultBaseDelay is the first backoff delay, doubled on every retry.
+	defaultBaseDelay = time.Second
+	// defaultMaxDelay caps the time waited before a single retry.
+	// Rate limits that reset later than this fail instead of stalling the rotation.
+	defaultMaxDelay = 2 * time.Minute
+	// secondaryRateLimitDelay is the minimum wait GitHub recommends after a secondary rate limit without a Retry-After header.
+	secondaryRateLimitDelay = time.Minute
+)
+
+// retryTransport retries requests that fail with transient server errors or GitHub rate limits.
+// Server errors are only retried for idempotent methods, since a POST may have been applied before the error was returned.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	// now and sleep are replaced in tests.
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+	// onRetry is called before waiting to retry a request.
+	onRetry func(req *http.Request, reason string, wait time.Duration)
+}
+
+// newRetryTransport returns a retryTransport wrapping base with the default limits.
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		base:       base,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip sends the request, retrying it while the response is retryable.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries {
+			return resp, err
+		}
+
+		reason, wait, retry := t.retryAfter(req, resp, attempt)
+		if !retry || wait > t.maxDelay {
+			return resp, nil
+		}
+
+		// The request body has been consumed and must be replayed.
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		// Drain the response so that the connection can be reused.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if t.onRetry != nil {
+			t.onRetry(req, reason, wait)
+		}
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter reports whether the response to the request should be retried, why, and how long to wait first.
+func (t *retryTransport) retryAfter(req *http.Request, resp *http.Response, attempt int) (string, time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+			return "secondary rate limit", wait, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return "primary rate limit", max(time.Unix(reset, 0).Sub(t.now()), 0) + time.Second, true
+			}
+		}
+		if isSecondaryRateLimit(resp) {
+			return "secondary rate limit", max(t.backoff(attempt), secondaryRateLimitDelay), true
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return "too many requests", t.backoff(attempt), true
+		}
+		// Any other 403 is a permission error, which retrying will not fix.
+		return "", 0, false
+	case !isIdempotent(req.Method):
+		// GitHub rejects rate limited requests before processing them, but a server error
+		// may follow a change that was already written, e.g. a deploy key that was created.
+		return "", 0, false
+	case resp.StatusCode == http.StatusInternalServerError,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return fmt.Sprintf("server error %d", resp.StatusCode), t.backoff(attempt), true
+	}
+	return "", 0, false
+}
+
+// isIdempotent reports whether sending the request twice has the same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the exponential backoff delay for the attempt, with jitter.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << attempt
+	if delay <= 0 || delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+	// Spread retries of parallel updates so that they do not hit the API at the same time.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// isSecondaryRateLimit reports whether the response body describes a secondary rate limit.
+// The body is restored so that it can still be read by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(b)), "secondary rate limit")
+}
+
+// sleepContext waits for the duration, or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,259 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		responses    []func(w http.ResponseWriter)
+		wantStatus   int
+		wantRequests int
+		wantWaits    []time.Duration
+	}{
+		{
+			name: "success is not retried",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusOK),
+			},
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+		},
+		{
+			name: "transient server errors are retried",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusBadGateway),
+				status(http.StatusServiceUnavailable),
+				status(http.StatusNoContent),
+			},
+			wantStatus:   http.StatusNoContent,
+			wantRequests: 3,
+		},
+		{
+			name: "Retry-After is honoured",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "30")
+					w.WriteHeader(http.StatusForbidden)
+				},
+				status(http.StatusCreated),
+			},
+			wantStatus:   http.StatusCreated,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{30 * time.Second},
+		},
+		{
+			name: "primary rate limit waits for the reset",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(10*time.Second).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				status(http.StatusOK),
+			},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{11 * time.Second},
+		},
+		{
+			name: "secondary rate limit without Retry-After waits at least a minute",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					io.WriteString(w, `{"message": "You have exceeded a secondary rate limit."}`)
+				},
+				status(http.StatusOK),
+			},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{time.Minute},
+		},
+		{
+			name: "rate limit resetting too late is not waited for",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name: "permission errors are not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					io.WriteString(w, `{"message": "Resource not accessible by integration"}`)
+				},
+			},
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name: "client errors are not retried",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusNotFound),
+			},
+			wantStatus:   http.StatusNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:   "server errors are not retried for POST",
+			method: http.MethodPost,
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusBadGateway),
+			},
+			wantStatus:   http.StatusBadGateway,
+			wantRequests: 1,
+		},
+		{
+			name:   "rate limits are retried for POST",
+			method: http.MethodPost,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "30")
+					w.WriteHeader(http.StatusForbidden)
+				},
+				status(http.StatusCreated),
+			},
+			wantStatus:   http.StatusCreated,
+			wantRequests: 2,
+			wantWaits:    []time.Duration{30 * time.Second},
+		},
+		{
+			name: "gives up after the maximum number of retries",
+			responses: []func(w http.ResponseWriter){
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+				status(http.StatusInternalServerError),
+			},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				tt.responses[requests](w)
+				requests++
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			transport := newRetryTransport(http.DefaultTransport)
+			transport.maxRetries = 2
+			transport.now = func() time.Time { return now }
+			transport.sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			client := &http.Client{Transport: transport}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPut
+			}
+			req, err := http.NewRequest(method, server.URL, strings.NewReader(`{"encrypted_value":"abc"}`))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, requests)
+			}
+			for i, body := range bodies {
+				if body != `{"encrypted_value":"abc"}` {
+					t.Errorf("Expected request %d to replay the body, got %q", i, body)
+				}
+			}
+			if len(waits) != tt.wantRequests-1 {
+				t.Errorf("Expected %d waits, got %v", tt.wantRequests-1, waits)
+			}
+			if tt.wantWaits != nil && !slices.Equal(waits, tt.wantWaits) {
+				t.Errorf("Expected waits %v, got %v", tt.wantWaits, waits)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Cancel the request while it waits to be retried.
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := newRetryTransport(http.DefaultTransport)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "HTTP date", value: now.Add(5 * time.Second).Format(http.TimeFormat), want: 5 * time.Second, wantOK: true},
+		{name: "date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "empty", value: ""},
+		{name: "invalid", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, expected %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func status(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(code)
+	}
+}
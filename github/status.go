@@ -0,0 +1,53 @@
+package github
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// SecretStatus describes the value stored in a destination, as reported by GitHub.
+type SecretStatus struct {
+	// Exists is false when the secret, variable, webhook or deploy key is not found in the destination.
+	Exists bool
+	// UpdatedAt is when the value was last written, or zero if GitHub does not report it.
+	UpdatedAt time.Time
+}
+
+// secretStatus returns the status of a secret fetched with get. A secret that is not found does not exist.
+func secretStatus(get func() (*github.Secret, *github.Response, error)) (SecretStatus, error) {
+	s, resp, err := get()
+	if isNotFound(resp) {
+		return SecretStatus{}, nil
+	}
+	if err != nil {
+		return SecretStatus{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+	return SecretStatus{Exists: true, UpdatedAt: s.UpdatedAt.Time}, nil
+}
+
+// variableStatus returns the status of a variable fetched with get. A variable that is not found does not exist.
+func variableStatus(get func() (*github.ActionsVariable, *github.Response, error)) (SecretStatus, error) {
+	v, resp, err := get()
+	if isNotFound(resp) {
+		return SecretStatus{}, nil
+	}
+	if err != nil {
+		return SecretStatus{}, fmt.Errorf("failed to get variable: %w", err)
+	}
+	return SecretStatus{Exists: true, UpdatedAt: v.GetUpdatedAt().Time}, nil
+}
+
+// hookStatus returns the status of a webhook fetched with get. A webhook that is not found does not exist.
+// GitHub only reports when the webhook was last changed, which is not necessarily when its secret was.
+func hookStatus(get func() (*github.Hook, *github.Response, error)) (SecretStatus, error) {
+	hook, resp, err := get()
+	if isNotFound(resp) {
+		return SecretStatus{}, nil
+	}
+	if err != nil {
+		return SecretStatus{}, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return SecretStatus{Exists: true, UpdatedAt: hook.GetUpdatedAt().Time}, nil
+}
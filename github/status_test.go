@@ -0,0 +1,125 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v69/github"
+)
+
+func TestStatus(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		destination interface {
+			Status(ctx context.Context, client Client) (SecretStatus, error)
+		}
+		handlers    map[string]http.HandlerFunc
+		want        SecretStatus
+		expectError bool
+	}{
+		{
+			name:        "repository secret",
+			destination: RepositorySecret{Repo: "o/r", Name: "MY_SECRET"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+				"GET /repos/o/r/actions/secrets/MY_SECRET": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"name":"MY_SECRET","updated_at":"2025-03-01T12:00:00Z"}`)
+				},
+			},
+			want: SecretStatus{Exists: true, UpdatedAt: updatedAt},
+		},
+		{
+			name:        "missing repository secret",
+			destination: RepositorySecret{Repo: "o/r", Name: "MY_SECRET"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"id":1}`)
+				},
+			},
+			want: SecretStatus{},
+		},
+		{
+			name:        "missing repository",
+			destination: RepositorySecret{Repo: "o/typo", Name: "MY_SECRET"},
+			expectError: true,
+		},
+		{
+			name:        "organization variable",
+			destination: OrganizationVariable{Org: "o", Name: "MY_VARIABLE"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o/actions/variables/MY_VARIABLE": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"name":"MY_VARIABLE","updated_at":"2025-03-01T12:00:00Z"}`)
+				},
+			},
+			want: SecretStatus{Exists: true, UpdatedAt: updatedAt},
+		},
+		{
+			name:        "organization secret without permission",
+			destination: OrganizationSecret{Org: "o", Name: "MY_SECRET"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o/actions/secrets/MY_SECRET": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:        "webhook selected by a missing url",
+			destination: OrganizationWebhook{Org: "o", URL: "https://example.com/hook"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /orgs/o/hooks": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `[]`)
+				},
+			},
+			want: SecretStatus{},
+		},
+		{
+			name:        "newest deploy key with the title",
+			destination: DeployKey{Repo: "o/r", Title: "deploy"},
+			handlers: map[string]http.HandlerFunc{
+				"GET /repos/o/r/keys": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `[
+						{"id":1,"title":"deploy","created_at":"2025-01-01T12:00:00Z"},
+						{"id":2,"title":"deploy","created_at":"2025-03-01T12:00:00Z"},
+						{"id":3,"title":"other","created_at":"2025-04-01T12:00:00Z"}
+					]`)
+				},
+			},
+			want: SecretStatus{Exists: true, UpdatedAt: updatedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mux, _ := setup(t)
+			for pattern, handler := range tt.handlers {
+				mux.HandleFunc(pattern, handler)
+			}
+
+			got, err := tt.destination.Status(context.Background(), client)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Status error = %v, expectError %v", err, tt.expectError)
+			}
+			if !got.UpdatedAt.Equal(tt.want.UpdatedAt) || got.Exists != tt.want.Exists {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindWebhook_NotFound(t *testing.T) {
+	_, err := findWebhook(0, "https://example.com/hook", func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return nil, &github.Response{}, nil
+	})
+	if !errors.Is(err, errWebhookNotFound) {
+		t.Errorf("Expected errWebhookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package github
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// storeVersion is the version of the credential store file format.
+	storeVersion = 1
+	// scrypt parameters used to derive the store key from its passphrase.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// errWrongPassphrase is returned when the credential store cannot be decrypted.
+var errWrongPassphrase = errors.New("wrong passphrase for the credential store, or the store is corrupted")
+
+// CredentialStore is a local file holding a token per host, encrypted with a passphrase.
+type CredentialStore struct {
+	Path string
+}
+
+// storeFile is the encrypted content of the credential store.
+type storeFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// DefaultCredentialStore returns the credential store in the user's configuration directory,
+// or at the path in the KEY_ROTATOR_CREDENTIALS_FILE environment variable.
+func DefaultCredentialStore() (CredentialStore, error) {
+	if path := os.Getenv("KEY_ROTATOR_CREDENTIALS_FILE"); path != "" {
+		return CredentialStore{Path: path}, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return CredentialStore{}, fmt.Errorf("failed to find the configuration directory: %v", err)
+	}
+	return CredentialStore{Path: filepath.Join(dir, "key-rotator", "credentials.json")}, nil
+}
+
+// Exists reports whether the credential store file exists.
+func (s CredentialStore) Exists() bool {
+	_, err := os.Stat(s.Path)
+	return err == nil
+}
+
+// Load decrypts the store and returns the token of each host.
+// A store that does not exist yet is empty.
+func (s CredentialStore) Load(passphrase string) (map[string]string, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the credential store: %v", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the credential store %s: %v", s.Path, err)
+	}
+	if file.Version != storeVersion {
+		return nil, fmt.Errorf("unsupported credential store version %d in %s", file.Version, s.Path)
+	}
+	if len(file.Nonce) != 24 {
+		return nil, errWrongPassphrase
+	}
+
+	key, err := storeKey(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], file.Nonce)
+	plaintext, ok := secretbox.Open(nil, file.Data, &nonce, key)
+	if !ok {
+		return nil, errWrongPassphrase
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, errWrongPassphrase
+	}
+	return tokens, nil
+}
+
+// Save encrypts the tokens with the passphrase and writes them to the store.
+func (s CredentialStore) Save(passphrase string, tokens map[string]string) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %v", err)
+	}
+
+	file := storeFile{Version: storeVersion, Salt: make([]byte, 16), Nonce: make([]byte, 24)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %v", err)
+	}
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	key, err := storeKey(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	copy(nonce[:], file.Nonce)
+	file.Data = secretbox.Seal(nil, plaintext, &nonce, key)
+
+	b, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the credential store: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("failed to create the credential store directory: %v", err)
+	}
+	if err := os.WriteFile(s.Path, b, 0o600); err != nil {
+		return fmt.Errorf("failed to write the credential store: %v", err)
+	}
+	return nil
+}
+
+// storeKey derives the encryption key of the store from its passphrase.
+func storeKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the credential store key: %v", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
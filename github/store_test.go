@@ -0,0 +1,61 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCredentialStore(t *testing.T) {
+	store := CredentialStore{Path: filepath.Join(t.TempDir(), "key-rotator", "credentials.json")}
+
+	if store.Exists() {
+		t.Fatalf("Expected the store not to exist")
+	}
+	tokens, err := store.Load("passphrase")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("Expected an empty store, got %v", tokens)
+	}
+
+	want := map[string]string{"github.com": "dotcom-token", "github.example.com": "enterprise-token"}
+	if err := store.Save("passphrase", want); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !store.Exists() {
+		t.Fatalf("Expected the store to exist")
+	}
+
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("Expected mode 0600, got %o", mode)
+	}
+	b, err := os.ReadFile(store.Path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(string(b), "dotcom-token") {
+		t.Errorf("Expected the tokens to be encrypted, got %s", b)
+	}
+
+	got, err := store.Load("passphrase")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if _, err := store.Load("wrong"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("Expected a wrong passphrase error, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// RepositoryVariable represents a GitHub Actions repository variable destination.
+type RepositoryVariable struct {
+	Repo      string `yaml:"repo"`
+	Name      string `yaml:"name"`
+	Plaintext bool   `yaml:"plaintext"`
+}
+
+// GetDescription returns the destination description.
+func (d RepositoryVariable) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Repository Variable in the %s repository", d.Name, d.Repo)
+}
+
+// Owner returns the owner of the repository.
+func (d RepositoryVariable) Owner() string {
+	return repoOwner(d.Repo)
+}
+
+// AllowsPlaintext reports whether the variable may hold a value that is also stored in encrypted secrets.
+func (d RepositoryVariable) AllowsPlaintext() bool {
+	return d.Plaintext
+}
+
+// UpdateSecret creates or updates the GitHub Actions variable in the repository.
+// Variables are not secret, so the value is stored in plaintext.
+func (d RepositoryVariable) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	v := &github.ActionsVariable{
+		Name:  d.Name,
+		Value: secretValue,
+	}
+	return repositoryAPIError(d.Repo, client.updateRepositoryVariable(ctx, owner, repo, v))
+}
+
+// Check verifies that the repository exists and that its variables can be read.
+func (d RepositoryVariable) Check(ctx context.Context, client Client) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Actions.ListRepoVariables(ctx, owner, repo, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return repositoryAPIError(d.Repo, fmt.Errorf("failed to list variables: %w", err))
+	}
+	return nil
+}
+
+// Status reports whether the variable exists in the repository and when it was last updated.
+func (d RepositoryVariable) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := variableStatus(func() (*github.ActionsVariable, *github.Response, error) {
+		return client.Actions.GetRepoVariable(ctx, owner, repo, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// RepositoryEnvironmentVariable represents a GitHub Actions environment variable destination.
+type RepositoryEnvironmentVariable struct {
+	Repo        string `yaml:"repo"`
+	Name        string `yaml:"name"`
+	Environment string `yaml:"environment"`
+	Plaintext   bool   `yaml:"plaintext"`
+}
+
+// GetDescription returns the destination description.
+func (d RepositoryEnvironmentVariable) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Repository Environment Variable in the %s repository's %s environment", d.Name, d.Repo, d.Environment)
+}
+
+// Owner returns the owner of the repository.
+func (d RepositoryEnvironmentVariable) Owner() string {
+	return repoOwner(d.Repo)
+}
+
+// AllowsPlaintext reports whether the variable may hold a value that is also stored in encrypted secrets.
+func (d RepositoryEnvironmentVariable) AllowsPlaintext() bool {
+	return d.Plaintext
+}
+
+// UpdateSecret creates or updates the GitHub Actions environment variable in the repository.
+// Variables are not secret, so the value is stored in plaintext.
+func (d RepositoryEnvironmentVariable) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	v := &github.ActionsVariable{
+		Name:  d.Name,
+		Value: secretValue,
+	}
+	return apiError(d.Repo, client.updateEnvironmentVariable(ctx, owner, repo, d.Environment, v))
+}
+
+// Check verifies that the repository and its environment exist, and that the variables of the environment can be read.
+func (d RepositoryEnvironmentVariable) Check(ctx context.Context, client Client) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return err
+	}
+	if err := client.checkEnvironment(ctx, owner, repo, d.Environment); err != nil {
+		return err
+	}
+	_, _, err = client.Actions.ListEnvVariables(ctx, owner, repo, d.Environment, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return apiError(d.Repo, fmt.Errorf("failed to list variables: %w", err))
+	}
+	return nil
+}
+
+// Status reports whether the variable exists in the environment and when it was last updated.
+func (d RepositoryEnvironmentVariable) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	status, err := variableStatus(func() (*github.ActionsVariable, *github.Response, error) {
+		return client.Actions.GetEnvVariable(ctx, owner, repo, d.Environment, d.Name)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// OrganizationVariable represents a GitHub Actions organization variable destination.
+type OrganizationVariable struct {
+	Org                  string   `yaml:"org"`
+	Name                 string   `yaml:"name"`
+	Visibility           string   `yaml:"visibility"`
+	SelectedRepositories []string `yaml:"selected_repositories"`
+	Plaintext            bool     `yaml:"plaintext"`
+}
+
+// GetDescription returns the destination description.
+func (d OrganizationVariable) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Organization Variable in the %s organization", d.Name, d.Org)
+}
+
+// Owner returns the organization.
+func (d OrganizationVariable) Owner() string {
+	return d.Org
+}
+
+// AllowsPlaintext reports whether the variable may hold a value that is also stored in encrypted secrets.
+func (d OrganizationVariable) AllowsPlaintext() bool {
+	return d.Plaintext
+}
+
+// Validate checks the visibility against the selected repositories.
+func (d OrganizationVariable) Validate() error {
+	return validateOrganizationVisibility(d.Visibility, d.SelectedRepositories)
+}
+
+// UpdateSecret creates or updates the GitHub Actions variable in the organization.
+// Variables are not secret, so the value is stored in plaintext.
+func (d OrganizationVariable) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	visibility := organizationVisibility(d.Visibility, d.SelectedRepositories)
+
+	v := &github.ActionsVariable{
+		Name:       d.Name,
+		Value:      secretValue,
+		Visibility: github.Ptr(visibility),
+	}
+	if visibility == visibilitySelected {
+		repositoryIDs, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories)
+		if err != nil {
+			return err
+		}
+		ids := github.SelectedRepoIDs(repositoryIDs)
+		v.SelectedRepositoryIDs = &ids
+	}
+	return apiError(d.Org, client.updateOrganizationVariable(ctx, d.Org, v))
+}
+
+// Check verifies the visibility and the selected repositories, and that the variables of the organization can be read.
+func (d OrganizationVariable) Check(ctx context.Context, client Client) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if _, err := client.repositoryIDs(ctx, d.Org, d.SelectedRepositories); err != nil {
+		return err
+	}
+
+	_, _, err := client.Actions.ListOrgVariables(ctx, d.Org, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return apiError(d.Org, fmt.Errorf("failed to list variables: %w", err))
+	}
+	return nil
+}
+
+// Status reports whether the variable exists in the organization and when it was last updated.
+func (d OrganizationVariable) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	status, err := variableStatus(func() (*github.ActionsVariable, *github.Response, error) {
+		return client.Actions.GetOrgVariable(ctx, d.Org, d.Name)
+	})
+	return status, apiError(d.Org, err)
+}
+
+// updateRepositoryVariable updates a GitHub Actions variable in the repository,
+// creating it if it does not exist yet.
+func (ghc Client) updateRepositoryVariable(ctx context.Context, owner string, repo string, variable *github.ActionsVariable) error {
+	if ghc.dryRun {
+		if _, err := ghc.repositoryID(ctx, owner, repo); err != nil {
+			return err
+		}
+		return dryRunVariable(variable, func() (*github.ActionsVariable, *github.Response, error) {
+			return ghc.Actions.GetRepoVariable(ctx, owner, repo, variable.Name)
+		})
+	}
+	resp, err := ghc.Actions.UpdateRepoVariable(ctx, owner, repo, variable)
+	if isNotFound(resp) {
+		_, err = ghc.Actions.CreateRepoVariable(ctx, owner, repo, variable)
+	}
+	return err
+}
+
+// updateEnvironmentVariable updates a GitHub Actions variable in the repository's environment,
+// creating it if it does not exist yet.
+func (ghc Client) updateEnvironmentVariable(ctx context.Context, owner string, repo string, environment string, variable *github.ActionsVariable) error {
+	if ghc.dryRun {
+		if _, err := ghc.repositoryID(ctx, owner, repo); err != nil {
+			return err
+		}
+		if err := ghc.checkEnvironment(ctx, owner, repo, environment); err != nil {
+			return err
+		}
+		return dryRunVariable(variable, func() (*github.ActionsVariable, *github.Response, error) {
+			return ghc.Actions.GetEnvVariable(ctx, owner, repo, environment, variable.Name)
+		})
+	}
+	resp, err := ghc.Actions.UpdateEnvVariable(ctx, owner, repo, environment, variable)
+	if isNotFound(resp) {
+		_, err = ghc.Actions.CreateEnvVariable(ctx, owner, repo, environment, variable)
+	}
+	return err
+}
+
+// updateOrganizationVariable updates a GitHub Actions variable in the organization,
+// creating it if it does not exist yet.
+func (ghc Client) updateOrganizationVariable(ctx context.Context, org string, variable *github.ActionsVariable) error {
+	if ghc.dryRun {
+		if _, _, err := ghc.Organizations.Get(ctx, org); err != nil {
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		return dryRunVariable(variable, func() (*github.ActionsVariable, *github.Response, error) {
+			return ghc.Actions.GetOrgVariable(ctx, org, variable.Name)
+		})
+	}
+	resp, err := ghc.Actions.UpdateOrgVariable(ctx, org, variable)
+	if isNotFound(resp) {
+		_, err = ghc.Actions.CreateOrgVariable(ctx, org, variable)
+	}
+	return err
+}
+
+// dryRunVariable validates the variable and reads its current value with get, so that a dry run fails
+// when the variables of its scope cannot be read. A variable that does not exist yet would be created.
+func dryRunVariable(variable *github.ActionsVariable, get func() (*github.ActionsVariable, *github.Response, error)) error {
+	if err := validateVariable(variable); err != nil {
+		return err
+	}
+	_, resp, err := get()
+	if isNotFound(resp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get variable: %w", err)
+	}
+	return nil
+}
+
+// isNotFound reports whether the GitHub API responded with a 404 status.
+func isNotFound(resp *github.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v69/github"
+)
+
+func TestRepositoryVariable_UpdateSecret_ExistingVariable(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r/actions/variables/MY_VARIABLE", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		var reqBody github.ActionsVariable
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.Value != "myvalue" {
+			t.Errorf("Expected value myvalue, got %s", reqBody.Value)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	v := RepositoryVariable{
+		Repo: "o/r",
+		Name: "MY_VARIABLE",
+	}
+	err := v.UpdateSecret(ctx, client, "myvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestRepositoryVariable_UpdateSecret_NewVariable(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r/actions/variables/MY_VARIABLE", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	created := false
+	mux.HandleFunc("/repos/o/r/actions/variables", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		var reqBody github.ActionsVariable
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.Name != "MY_VARIABLE" || reqBody.Value != "myvalue" {
+			t.Errorf("Unexpected variable %+v", reqBody)
+		}
+		created = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ctx := context.Background()
+	v := RepositoryVariable{
+		Repo: "o/r",
+		Name: "MY_VARIABLE",
+	}
+	err := v.UpdateSecret(ctx, client, "myvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !created {
+		t.Fatal("Expected the variable to be created")
+	}
+}
+
+func TestRepositoryVariable_UpdateSecret_InvalidRepo(t *testing.T) {
+	client, _, _ := setup(t)
+
+	ctx := context.Background()
+	v := RepositoryVariable{
+		Repo: "invalid/repo/format",
+		Name: "MY_VARIABLE",
+	}
+	err := v.UpdateSecret(ctx, client, "myvalue")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestRepositoryEnvironmentVariable_UpdateSecret_NewVariable(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r/environments/env/variables/MY_VARIABLE", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	created := false
+	mux.HandleFunc("/repos/o/r/environments/env/variables", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		created = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ctx := context.Background()
+	v := RepositoryEnvironmentVariable{
+		Repo:        "o/r",
+		Name:        "MY_VARIABLE",
+		Environment: "env",
+	}
+	err := v.UpdateSecret(ctx, client, "myvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !created {
+		t.Fatal("Expected the variable to be created")
+	}
+}
+
+func TestOrganizationVariable_UpdateSecret_SelectedRepositories(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id":1234}`)
+	})
+
+	mux.HandleFunc("/orgs/o/actions/variables/MY_VARIABLE", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		var reqBody github.ActionsVariable
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.GetVisibility() != "selected" {
+			t.Errorf("Expected visibility selected, got %s", reqBody.GetVisibility())
+		}
+		if diff := cmp.Diff(&github.SelectedRepoIDs{1234}, reqBody.SelectedRepositoryIDs); diff != "" {
+			t.Errorf("Unexpected selected repository IDs (-want +got):\n%s", diff)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	v := OrganizationVariable{
+		Org:                  "o",
+		Name:                 "MY_VARIABLE",
+		SelectedRepositories: []string{"r"},
+	}
+	err := v.UpdateSecret(ctx, client, "myvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
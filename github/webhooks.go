@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v69/github"
+)
+
+// RepositoryWebhook represents the secret of an existing GitHub repository webhook.
+// The webhook is selected by its ID or, when no ID is given, by its payload URL.
+type RepositoryWebhook struct {
+	Repo   string `yaml:"repo"`
+	HookID int64  `yaml:"hook_id"`
+	URL    string `yaml:"url"`
+}
+
+// GetDescription returns the destination description.
+func (d RepositoryWebhook) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Repository Webhook Secret in the %s repository", webhookSelector(d.HookID, d.URL), d.Repo)
+}
+
+// Owner returns the owner of the repository.
+func (d RepositoryWebhook) Owner() string {
+	return repoOwner(d.Repo)
+}
+
+// UpdateSecret updates the secret of the webhook in the repository.
+func (d RepositoryWebhook) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Repositories.ListHooks(ctx, owner, repo, opts)
+	})
+	if err != nil {
+		return repositoryAPIError(d.Repo, err)
+	}
+
+	if client.dryRun {
+		err = checkHook(hookID, func() (*github.Hook, *github.Response, error) {
+			return client.Repositories.GetHook(ctx, owner, repo, hookID)
+		})
+		return repositoryAPIError(d.Repo, err)
+	}
+
+	_, _, err = client.Repositories.EditHookConfiguration(ctx, owner, repo, hookID, &github.HookConfig{
+		Secret: github.Ptr(secretValue),
+	})
+	return apiError(d.Repo, err)
+}
+
+// Check verifies that the webhook exists in the repository and can be read.
+func (d RepositoryWebhook) Check(ctx context.Context, client Client) error {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return err
+	}
+
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Repositories.ListHooks(ctx, owner, repo, opts)
+	})
+	if err != nil {
+		return repositoryAPIError(d.Repo, err)
+	}
+
+	err = checkHook(hookID, func() (*github.Hook, *github.Response, error) {
+		return client.Repositories.GetHook(ctx, owner, repo, hookID)
+	})
+	return repositoryAPIError(d.Repo, err)
+}
+
+// Status reports whether the webhook exists in the repository and when it was last changed.
+func (d RepositoryWebhook) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	owner, repo, err := splitRepo(d.Repo)
+	if err != nil {
+		return SecretStatus{}, err
+	}
+	if _, err := client.repositoryID(ctx, owner, repo); err != nil {
+		return SecretStatus{}, err
+	}
+
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Repositories.ListHooks(ctx, owner, repo, opts)
+	})
+	if errors.Is(err, errWebhookNotFound) {
+		return SecretStatus{}, nil
+	}
+	if err != nil {
+		return SecretStatus{}, apiError(d.Repo, err)
+	}
+
+	status, err := hookStatus(func() (*github.Hook, *github.Response, error) {
+		return client.Repositories.GetHook(ctx, owner, repo, hookID)
+	})
+	return status, apiError(d.Repo, err)
+}
+
+// OrganizationWebhook represents the secret of an existing GitHub organization webhook.
+// The webhook is selected by its ID or, when no ID is given, by its payload URL.
+type OrganizationWebhook struct {
+	Org    string `yaml:"org"`
+	HookID int64  `yaml:"hook_id"`
+	URL    string `yaml:"url"`
+}
+
+// GetDescription returns the destination description.
+func (d OrganizationWebhook) GetDescription() string {
+	return fmt.Sprintf("%s GitHub Organization Webhook Secret in the %s organization", webhookSelector(d.HookID, d.URL), d.Org)
+}
+
+// Owner returns the organization.
+func (d OrganizationWebhook) Owner() string {
+	return d.Org
+}
+
+// UpdateSecret updates the secret of the webhook in the organization.
+func (d OrganizationWebhook) UpdateSecret(ctx context.Context, client Client, secretValue string) error {
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Organizations.ListHooks(ctx, d.Org, opts)
+	})
+	if err != nil {
+		return apiError(d.Org, err)
+	}
+
+	if client.dryRun {
+		err = checkHook(hookID, func() (*github.Hook, *github.Response, error) {
+			return client.Organizations.GetHook(ctx, d.Org, hookID)
+		})
+		return apiError(d.Org, err)
+	}
+
+	_, _, err = client.Organizations.EditHookConfiguration(ctx, d.Org, hookID, &github.HookConfig{
+		Secret: github.Ptr(secretValue),
+	})
+	return apiError(d.Org, err)
+}
+
+// Check verifies that the webhook exists in the organization and can be read.
+func (d OrganizationWebhook) Check(ctx context.Context, client Client) error {
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Organizations.ListHooks(ctx, d.Org, opts)
+	})
+	if err != nil {
+		return apiError(d.Org, err)
+	}
+
+	err = checkHook(hookID, func() (*github.Hook, *github.Response, error) {
+		return client.Organizations.GetHook(ctx, d.Org, hookID)
+	})
+	return apiError(d.Org, err)
+}
+
+// Status reports whether the webhook exists in the organization and when it was last changed.
+func (d OrganizationWebhook) Status(ctx context.Context, client Client) (SecretStatus, error) {
+	hookID, err := findWebhook(d.HookID, d.URL, func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return client.Organizations.ListHooks(ctx, d.Org, opts)
+	})
+	if errors.Is(err, errWebhookNotFound) {
+		return SecretStatus{}, nil
+	}
+	if err != nil {
+		return SecretStatus{}, apiError(d.Org, err)
+	}
+
+	status, err := hookStatus(func() (*github.Hook, *github.Response, error) {
+		return client.Organizations.GetHook(ctx, d.Org, hookID)
+	})
+	return status, apiError(d.Org, err)
+}
+
+// errWebhookNotFound is returned when no webhook matches the configured payload URL.
+var errWebhookNotFound = errors.New("no webhook found")
+
+// findWebhook returns the ID of the webhook to update.
+// When no hook ID is configured, the webhooks are listed and matched against the payload URL.
+func findWebhook(hookID int64, url string, listHooks func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error)) (int64, error) {
+	if hookID != 0 {
+		return hookID, nil
+	}
+	if url == "" {
+		return 0, fmt.Errorf("either hook_id or url is required to select a webhook")
+	}
+
+	var matches []int64
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		hooks, resp, err := listHooks(opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+		for _, hook := range hooks {
+			if hook.GetConfig().GetURL() == url {
+				matches = append(matches, hook.GetID())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("%w with url %s", errWebhookNotFound, url)
+	case 1:
+		return matches[0], nil
+	default:
+		return 0, fmt.Errorf("found %d webhooks with url %s, use hook_id to select one", len(matches), url)
+	}
+}
+
+// webhookSelector describes how a webhook is selected.
+func webhookSelector(hookID int64, url string) string {
+	if hookID != 0 {
+		return fmt.Sprintf("Hook %d", hookID)
+	}
+	return url
+}
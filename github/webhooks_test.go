@@ -0,0 +1,120 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v69/github"
+)
+
+func TestRepositoryWebhook_UpdateSecret_HookID(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r/hooks/1/config", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		var reqBody github.HookConfig
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if reqBody.GetSecret() != "mysecretvalue" {
+			t.Errorf("Expected secret mysecretvalue, got %s", reqBody.GetSecret())
+		}
+		if reqBody.URL != nil {
+			t.Errorf("Expected the url to be left unchanged, got %s", reqBody.GetURL())
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx := context.Background()
+	h := RepositoryWebhook{
+		Repo:   "o/r",
+		HookID: 1,
+	}
+	err := h.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestRepositoryWebhook_UpdateSecret_URL(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/repos/o/r/hooks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":1,"config":{"url":"https://example.com/other"}},{"id":2,"config":{"url":"https://example.com/webhook"}}]`)
+	})
+
+	mux.HandleFunc("/repos/o/r/hooks/2/config", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx := context.Background()
+	h := RepositoryWebhook{
+		Repo: "o/r",
+		URL:  "https://example.com/webhook",
+	}
+	err := h.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestRepositoryWebhook_UpdateSecret_MissingSelector(t *testing.T) {
+	client, _, _ := setup(t)
+
+	ctx := context.Background()
+	h := RepositoryWebhook{
+		Repo: "o/r",
+	}
+	err := h.UpdateSecret(ctx, client, "mysecretvalue")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestOrganizationWebhook_UpdateSecret_AmbiguousURL(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/orgs/o/hooks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":1,"config":{"url":"https://example.com/webhook"}},{"id":2,"config":{"url":"https://example.com/webhook"}}]`)
+	})
+
+	ctx := context.Background()
+	h := OrganizationWebhook{
+		Org: "o",
+		URL: "https://example.com/webhook",
+	}
+	err := h.UpdateSecret(ctx, client, "mysecretvalue")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestOrganizationWebhook_UpdateSecret_URL(t *testing.T) {
+	client, mux, _ := setup(t)
+
+	mux.HandleFunc("/orgs/o/hooks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id":1,"config":{"url":"https://example.com/webhook"}}]`)
+	})
+
+	mux.HandleFunc("/orgs/o/hooks/1/config", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PATCH")
+		fmt.Fprint(w, `{}`)
+	})
+
+	ctx := context.Background()
+	h := OrganizationWebhook{
+		Org: "o",
+		URL: "https://example.com/webhook",
+	}
+	err := h.UpdateSecret(ctx, client, "mysecretvalue")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
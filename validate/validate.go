@@ -0,0 +1,128 @@
+package validate
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Formats a value can be required to parse as.
+const (
+	FormatPEM           = "pem"
+	FormatX509          = "x509"
+	FormatSSHPrivateKey = "ssh-private-key"
+	FormatSSHPublicKey  = "ssh-public-key"
+)
+
+// Rules describes the checks a secret value must pass before it is written to any destination.
+type Rules struct {
+	// MinLength and MaxLength bound the length of the value in bytes.
+	MinLength int `yaml:"min_length"`
+	MaxLength int `yaml:"max_length"`
+	// Regex must match the value.
+	Regex string `yaml:"regex"`
+	// JSON requires the value to be valid JSON.
+	JSON bool `yaml:"json"`
+	// Base64 requires the value to be standard base64.
+	Base64 bool `yaml:"base64"`
+	// Format requires the value to parse as a PEM block, an X.509 certificate or an SSH key.
+	Format string `yaml:"format"`
+	// ForbidSurroundingWhitespace rejects values with leading or trailing whitespace, including newlines.
+	ForbidSurroundingWhitespace bool `yaml:"forbid_surrounding_whitespace"`
+}
+
+// Validate checks that the rules themselves are valid.
+func (r Rules) Validate() error {
+	if r.MinLength < 0 || r.MaxLength < 0 {
+		return fmt.Errorf("min_length and max_length must not be negative")
+	}
+	if r.MaxLength != 0 && r.MinLength > r.MaxLength {
+		return fmt.Errorf("min_length %d is greater than max_length %d", r.MinLength, r.MaxLength)
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	switch r.Format {
+	case "", FormatPEM, FormatX509, FormatSSHPrivateKey, FormatSSHPublicKey:
+	default:
+		return fmt.Errorf("unsupported format: %s", r.Format)
+	}
+	return nil
+}
+
+// Check returns an error describing every rule the value does not pass.
+// The value itself is never included in the error.
+func (r Rules) Check(value string) error {
+	var errs []error
+	if r.MinLength != 0 && len(value) < r.MinLength {
+		errs = append(errs, fmt.Errorf("must be at least %d bytes long, got %d", r.MinLength, len(value)))
+	}
+	if r.MaxLength != 0 && len(value) > r.MaxLength {
+		errs = append(errs, fmt.Errorf("must be at most %d bytes long, got %d", r.MaxLength, len(value)))
+	}
+	if r.Regex != "" {
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid regex: %v", err))
+		} else if !re.MatchString(value) {
+			errs = append(errs, fmt.Errorf("must match %s", r.Regex))
+		}
+	}
+	if r.JSON && !json.Valid([]byte(value)) {
+		errs = append(errs, errors.New("must be valid JSON"))
+	}
+	if r.Base64 {
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			errs = append(errs, errors.New("must be valid base64"))
+		}
+	}
+	if r.Format != "" {
+		if err := checkFormat(r.Format, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.ForbidSurroundingWhitespace && strings.TrimSpace(value) != value {
+		errs = append(errs, errors.New("must not have leading or trailing whitespace"))
+	}
+	return errors.Join(errs...)
+}
+
+// checkFormat checks that the value parses in the given format.
+func checkFormat(format string, value string) error {
+	switch format {
+	case FormatPEM:
+		if block, _ := pem.Decode([]byte(value)); block == nil {
+			return errors.New("must be PEM encoded")
+		}
+	case FormatX509:
+		block, _ := pem.Decode([]byte(value))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return errors.New("must be a PEM encoded X.509 certificate")
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("must be a valid X.509 certificate: %v", err)
+		}
+	case FormatSSHPrivateKey:
+		// Encrypted keys cannot be fully parsed without their passphrase, but are well-formed.
+		var passphraseErr *ssh.PassphraseMissingError
+		if _, err := ssh.ParseRawPrivateKey([]byte(value)); err != nil && !errors.As(err, &passphraseErr) {
+			return fmt.Errorf("must be a valid SSH private key: %v", err)
+		}
+	case FormatSSHPublicKey:
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value)); err != nil {
+			return fmt.Errorf("must be a valid SSH public key: %v", err)
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	return nil
+}
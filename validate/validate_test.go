@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestRules_Check(t *testing.T) {
+	sshPrivateKey, sshPublicKey, certificate := testKeys(t)
+
+	tests := []struct {
+		name        string
+		rules       Rules
+		value       string
+		expectError bool
+	}{
+		{name: "no rules", rules: Rules{}, value: " anything\n"},
+		{name: "min length", rules: Rules{MinLength: 4}, value: "abcd"},
+		{name: "too short", rules: Rules{MinLength: 4}, value: "abc", expectError: true},
+		{name: "too long", rules: Rules{MaxLength: 2}, value: "abc", expectError: true},
+		{name: "regex", rules: Rules{Regex: `^ghp_[A-Za-z0-9]+$`}, value: "ghp_abc123"},
+		{name: "regex mismatch", rules: Rules{Regex: `^ghp_[A-Za-z0-9]+$`}, value: "gho_abc123", expectError: true},
+		{name: "json", rules: Rules{JSON: true}, value: `{"type":"service_account"}`},
+		{name: "invalid json", rules: Rules{JSON: true}, value: `{"type":`, expectError: true},
+		{name: "base64", rules: Rules{Base64: true}, value: "c2VjcmV0"},
+		{name: "invalid base64", rules: Rules{Base64: true}, value: "not base64!", expectError: true},
+		{name: "pem", rules: Rules{Format: FormatPEM}, value: certificate},
+		{name: "invalid pem", rules: Rules{Format: FormatPEM}, value: "not pem", expectError: true},
+		{name: "x509", rules: Rules{Format: FormatX509}, value: certificate},
+		{name: "x509 with a private key", rules: Rules{Format: FormatX509}, value: sshPrivateKey, expectError: true},
+		{name: "ssh private key", rules: Rules{Format: FormatSSHPrivateKey}, value: sshPrivateKey},
+		{name: "ssh private key with a certificate", rules: Rules{Format: FormatSSHPrivateKey}, value: certificate, expectError: true},
+		{name: "ssh public key", rules: Rules{Format: FormatSSHPublicKey}, value: sshPublicKey},
+		{name: "invalid ssh public key", rules: Rules{Format: FormatSSHPublicKey}, value: "ssh-ed25519 invalid", expectError: true},
+		{name: "no surrounding whitespace", rules: Rules{ForbidSurroundingWhitespace: true}, value: "secret"},
+		{name: "trailing newline", rules: Rules{ForbidSurroundingWhitespace: true}, value: "secret\n", expectError: true},
+		{name: "leading space", rules: Rules{ForbidSurroundingWhitespace: true}, value: " secret", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Check(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Check() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRules_Check_DoesNotRevealValue(t *testing.T) {
+	rules := Rules{MinLength: 100, JSON: true, ForbidSurroundingWhitespace: true}
+	err := rules.Check("hunter2 ")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected the error not to contain the value, got %q", err)
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       Rules
+		expectError bool
+	}{
+		{name: "valid", rules: Rules{MinLength: 1, MaxLength: 10, Regex: `^\d+$`, Format: FormatPEM}},
+		{name: "negative length", rules: Rules{MinLength: -1}, expectError: true},
+		{name: "min greater than max", rules: Rules{MinLength: 10, MaxLength: 1}, expectError: true},
+		{name: "invalid regex", rules: Rules{Regex: `(`}, expectError: true},
+		{name: "unknown format", rules: Rules{Format: "der"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Validate()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+// testKeys returns an OpenSSH private key, its authorized key and a self-signed certificate.
+func testKeys(t *testing.T) (string, string, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sshPublicKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "key-rotator"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, public, private)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return string(pem.EncodeToMemory(block)),
+		string(ssh.MarshalAuthorizedKey(sshPublicKey)),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}